@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/munanadi/pokedex/pokecache"
+	"github.com/munanadi/pokedex/pokehelp"
+)
+
+// subcommand is a command run from the shell, like `pokedex pokemon inspect
+// pikachu`. It runs a command of the registry with words in front of its
+// arguments, so the REPL and the shell share the same implementation.
+type subcommand struct {
+	group       string
+	name        string
+	description string
+	// usage shows the arguments, like <POKEMON>
+	usage string
+	// command is the name in getCommands(), run with words and then the
+	// flags and arguments given
+	command string
+	words   []string
+	// minArgs and maxArgs bound the arguments, maxArgs -1 doesn't
+	minArgs int
+	maxArgs int
+	// flags adds the command's own flags. They're checked here, for
+	// --help and errors, and handed to the command as it parses them too.
+	flags func(flags *flag.FlagSet)
+	// storedCache commands look at the responses cached by earlier
+	// commands, which only --storage sqlite keeps
+	storedCache bool
+}
+
+// subcommands are the commands the binary takes, in the order the help
+// lists them. repl and serve-mock are run by main itself.
+var subcommands = []subcommand{
+	{group: "repl", description: "Starts the interactive pokedex, the same as no command at all"},
+	{group: "serve-mock", usage: "[--addr ADDR]", description: "Serves the mock API for demos and trying things out without pokeapi.co", maxArgs: -1},
+
+	{group: "pokemon", name: "catch", usage: "<POKEMON>", description: "Tries to catch a pokemon", command: "catch", minArgs: 1, maxArgs: 1},
+	{group: "pokemon", name: "inspect", usage: "<POKEMON>", description: "Shows the types, abilities and stats of a caught pokemon", command: "inspect", minArgs: 1, maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			flags.Var(&spriteValue{}, "sprite", "draws the sprite, --sprite=VARIANT picks one of "+strings.Join(pokehelp.SpriteVariants, ", "))
+		}},
+	{group: "pokemon", name: "moves", usage: "<POKEMON>", description: "Lists the moves of a caught pokemon", command: "moves", minArgs: 1, maxArgs: 1},
+	{group: "pokemon", name: "list", description: "Lists the caught pokemon and how many were seen", command: "pokedex",
+		flags: func(flags *flag.FlagSet) { pokedexFlags(flags, &pokehelp.PokedexFilter{}) }},
+	{group: "pokemon", name: "export", usage: "<FILE>", description: "Writes the caught pokemon to a file, - writes to the terminal", command: "export", minArgs: 1, maxArgs: 1,
+		flags: func(flags *flag.FlagSet) { formatFlag(flags, new(string)) }},
+	{group: "pokemon", name: "import", usage: "<FILE>", description: "Adds the pokemon in an exported file to the pokedex", command: "import", minArgs: 1, maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			formatFlag(flags, new(string))
+			replaceFlag(flags, new(bool))
+		}},
+
+	{group: "item", name: "list", description: "Lists the items in the bag", command: "inventory"},
+
+	{group: "area", name: "list", description: "Lists the location areas a page at a time", command: "map",
+		flags: func(flags *flag.FlagSet) { mapFlags(flags) }},
+	{group: "area", name: "explore", usage: "<AREA>", description: "Lists the pokemon in an area", command: "explore", minArgs: 1, maxArgs: 1},
+
+	{group: "region", name: "list", description: "Lists the regions", command: "region", words: []string{"list"}},
+	{group: "region", name: "set", usage: "<REGION>", description: "Picks the region locations are listed for", command: "region", minArgs: 1, maxArgs: 1},
+	{group: "location", name: "list", description: "Lists the locations in the picked region", command: "locations"},
+	{group: "location", name: "areas", usage: "<LOCATION>", description: "Lists the areas of a location", command: "areas", minArgs: 1, maxArgs: 1},
+
+	{group: "team", name: "show", description: "Shows the team", command: "team"},
+	{group: "team", name: "add", usage: "<POKEMON>", description: "Adds a caught pokemon to the team", command: "team", words: []string{"add"}, minArgs: 1, maxArgs: 1},
+	{group: "team", name: "remove", usage: "<POKEMON>", description: "Takes a pokemon off the team", command: "team", words: []string{"remove"}, minArgs: 1, maxArgs: 1},
+	{group: "team", name: "export", usage: "[FILE]", description: "Writes the team as a Showdown paste, to the terminal without a file", command: "team", words: []string{"export"}, maxArgs: 1},
+	{group: "team", name: "import", usage: "<FILE>", description: "Makes the pokemon in a Showdown paste the team", command: "team", words: []string{"import"}, minArgs: 1, maxArgs: 1,
+		flags: func(flags *flag.FlagSet) { replaceFlag(flags, new(bool)) }},
+
+	{group: "profile", name: "show", description: "Shows the profile in use", command: "profile"},
+	{group: "profile", name: "list", description: "Lists the profiles", command: "profile", words: []string{"list"}},
+	{group: "profile", name: "new", usage: "<NAME>", description: "Creates a profile", command: "profile", words: []string{"new"}, minArgs: 1, maxArgs: 1},
+	{group: "profile", name: "delete", usage: "<NAME>", description: "Deletes a profile", command: "profile", words: []string{"delete"}, minArgs: 1, maxArgs: 1},
+
+	{group: "config", name: "show", description: "Shows the settings and where each one comes from", command: "config"},
+	{group: "config", name: "get", usage: "<KEY>", description: "Shows a setting", command: "config", words: []string{"get"}, minArgs: 1, maxArgs: 1},
+	{group: "config", name: "set", usage: "<KEY> <VALUE>", description: "Writes a setting to the config file", command: "config", words: []string{"set"}, minArgs: 2, maxArgs: 2},
+
+	{group: "lang", name: "show", description: "Shows the language of the profile", command: "lang"},
+	{group: "lang", name: "set", usage: "<LANG>", description: "Sets the language of the profile", command: "lang", minArgs: 1, maxArgs: 1},
+	{group: "game", name: "show", description: "Shows the game the profile is filtered to", command: "game"},
+	{group: "game", name: "set", usage: "<VERSION>", description: "Sets the game the profile is filtered to, all clears it", command: "game", minArgs: 1, maxArgs: 1},
+
+	{group: "cache", name: "show", description: "Shows how many responses are cached, needs --storage sqlite", command: "cache", storedCache: true},
+	{group: "cache", name: "clear", description: "Throws out the cached responses and the name index, needs --storage sqlite", command: "cache", words: []string{"clear"}, storedCache: true},
+	{group: "snapshot", name: "show", description: "Shows what is in the offline snapshot", command: "snapshot"},
+	{group: "snapshot", name: "download", usage: "[KIND...]", description: "Downloads the API into the snapshot", command: "snapshot", words: []string{"download"}, maxArgs: -1},
+	{group: "snapshot", name: "import", usage: "<DIR>", description: "Imports a clone of the PokeAPI api-data repository into the snapshot", command: "snapshot", words: []string{"import"}, minArgs: 1, maxArgs: 1},
+}
+
+// path is how the subcommand is called, like `pokemon inspect`
+func (s subcommand) path() string {
+	return strings.TrimSpace(s.group + " " + s.name)
+}
+
+// synopsis is the path with the flags and arguments it takes
+func (s subcommand) synopsis() string {
+	parts := []string{s.path()}
+	if s.flags != nil {
+		parts = append(parts, "[flags]")
+	}
+	if s.usage != "" {
+		parts = append(parts, s.usage)
+	}
+	return strings.Join(parts, " ")
+}
+
+// flagSet is a FlagSet with the subcommand's flags whose usage is the
+// subcommand's help
+func (s subcommand) flagSet(output io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet("pokedex "+s.path(), flag.ContinueOnError)
+	flags.SetOutput(output)
+	if s.flags != nil {
+		s.flags(flags)
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(output, "Usage: pokedex %s\n\n%s\n", s.synopsis(), s.description)
+		if s.flags != nil {
+			fmt.Fprintln(output, "\nFlags:")
+			flags.PrintDefaults()
+		}
+	}
+	return flags
+}
+
+// findSubcommand looks up the subcommand args start with, the rest of
+// args are its flags and arguments
+func findSubcommand(args []string) (subcommand, []string, bool) {
+	for _, s := range subcommands {
+		if len(args) == 0 || args[0] != s.group {
+			continue
+		}
+		if s.name == "" {
+			return s, args[1:], true
+		}
+		if len(args) > 1 && args[1] == s.name {
+			return s, args[2:], true
+		}
+	}
+	return subcommand{}, nil, false
+}
+
+// isGroup says if name is the first word of a subcommand
+func isGroup(name string) bool {
+	return slices.ContainsFunc(subcommands, func(s subcommand) bool { return s.group == name })
+}
+
+// printUsage lists the subcommands of group, or all of them without one
+func printUsage(w io.Writer, group string) {
+	if group == "" {
+		fmt.Fprintln(w, "Usage: pokedex [flags] [command]")
+	} else {
+		fmt.Fprintf(w, "Usage: pokedex [flags] %s <command>\n", group)
+	}
+	fmt.Fprintln(w, "\nCommands:")
+
+	width := 0
+	for _, s := range subcommands {
+		width = max(width, len(s.synopsis()))
+	}
+	for _, s := range subcommands {
+		if group == "" || s.group == group {
+			fmt.Fprintf(w, "  %-*s  %s\n", width, s.synopsis(), s.description)
+		}
+	}
+
+	fmt.Fprintln(w, "\nRun `pokedex <command> --help` for the flags of a command, and `pokedex --help` for the flags every command takes.")
+}
+
+// printHelp is `pokedex help [command]`, the usage of everything, of a
+// group or of a single subcommand
+func printHelp(w io.Writer, args []string) bool {
+	if s, _, ok := findSubcommand(args); ok {
+		s.flagSet(w).Usage()
+		return true
+	}
+	if len(args) > 0 && isGroup(args[0]) {
+		printUsage(w, args[0])
+		return true
+	}
+	printUsage(w, "")
+	return len(args) == 0
+}
+
+// runCLI runs a subcommand of the registry for args and returns the exit
+// code: 0 if it ran, 1 if it failed and 2 if args are wrong. Usage and
+// flag errors go to stderr, what the command prints to config.Out.
+func runCLI(ctx context.Context, config *pokehelp.RequestConfig, stderr io.Writer, args []string) int {
+	s, rest, ok := findSubcommand(args)
+	if !ok || s.command == "" {
+		if len(args) > 0 && !isGroup(args[0]) {
+			fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		}
+		group := ""
+		if len(args) > 0 && isGroup(args[0]) {
+			group = args[0]
+		}
+		printUsage(stderr, group)
+		return 2
+	}
+
+	flags := s.flagSet(stderr)
+	arguments, err := parseInterspersed(flags, rest)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+	if len(arguments) < s.minArgs || s.maxArgs >= 0 && len(arguments) > s.maxArgs {
+		fmt.Fprintf(stderr, "pokedex %s takes %s\n\n", s.path(), argumentCount(s))
+		flags.Usage()
+		return 2
+	}
+	// Without the database every run starts with an empty cache
+	if _, ok := config.Cache.(*pokecache.Cache); ok && s.storedCache {
+		fmt.Fprintf(stderr, "pokedex %s needs --storage sqlite, the cache only lasts as long as a command without it\n", s.path())
+		return 1
+	}
+
+	words := append(slices.Clone(s.words), flagWords(flags)...)
+	words = append(words, arguments...)
+	if err := runCommand(ctx, config, getCommands()[s.command], words); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func argumentCount(s subcommand) string {
+	if s.maxArgs == 0 {
+		return "no arguments"
+	}
+	return s.usage
+}
+
+// parseInterspersed parses flags wherever they are in args, not only
+// before the arguments, so `pokemon inspect pikachu --sprite` works too. It
+// returns the arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var arguments []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		// Everything after -- is an argument
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(arguments, rest...), nil
+		}
+		if len(rest) == 0 {
+			return arguments, nil
+		}
+		arguments = append(arguments, rest[0])
+		args = rest[1:]
+	}
+}
+
+// flagWords are the flags that were set, written back the way the
+// registry's commands parse them
+func flagWords(flags *flag.FlagSet) []string {
+	var words []string
+	flags.Visit(func(f *flag.Flag) {
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() && f.Value.String() == "true" {
+			words = append(words, "--"+f.Name)
+			return
+		}
+		words = append(words, "--"+f.Name+"="+f.Value.String())
+	})
+	return words
+}
+
+// spriteValue is inspect's --sprite, which takes a variant only as
+// --sprite=VARIANT
+type spriteValue struct {
+	set     bool
+	variant string
+}
+
+func (v *spriteValue) IsBoolFlag() bool { return true }
+
+func (v *spriteValue) String() string {
+	switch {
+	case v == nil || !v.set:
+		return ""
+	case v.variant == "":
+		return "true"
+	}
+	return v.variant
+}
+
+func (v *spriteValue) Set(s string) error {
+	v.set = true
+	if s != "true" {
+		v.variant = s
+	}
+	return nil
+}
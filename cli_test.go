@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/munanadi/pokedex/pokehelp"
+	"github.com/munanadi/pokedex/pokestore"
+)
+
+func TestCLIUsage(t *testing.T) {
+	var out bytes.Buffer
+	printUsage(&out, "")
+	checkGolden(t, "cli_help", out.Bytes())
+}
+
+func TestSubcommandsRunRegistryCommands(t *testing.T) {
+	commands := getCommands()
+	for _, s := range subcommands {
+		if s.command == "" {
+			continue
+		}
+		if _, ok := commands[s.command]; !ok {
+			t.Errorf("pokedex %s runs %q which isn't a command", s.path(), s.command)
+		}
+		if found, _, ok := findSubcommand(strings.Fields(s.path())); !ok || found.path() != s.path() {
+			t.Errorf("pokedex %s isn't found by its path", s.path())
+		}
+	}
+}
+
+func TestRunCLI(t *testing.T) {
+	config := newSessionConfig(t, "")
+	var stderr bytes.Buffer
+
+	for _, test := range []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{args: []string{"area", "list", "--limit", "2"}, out: "canalave-city-area\neterna-city-area\npage 1 of 13 (26 locations)\n"},
+		{args: []string{"area", "list", "--page=2", "--limit=2"}, out: "pastoria-city-area\nsunyshore-city-area\npage 2 of 13 (26 locations)\n"},
+		{args: []string{"config", "get", "page_size"}, out: "20\n"},
+		{args: []string{"pokemon", "inspect", "pikachu", "--sprite"}, out: "you have not caught that pokemon\n"},
+		{args: []string{"pokemon", "inspect"}, code: 2},
+		{args: []string{"pokemon", "inspect", "pikachu", "--shiny"}, code: 2},
+		{args: []string{"config", "set", "lang"}, code: 2},
+		{args: []string{"pokemon"}, code: 2},
+		{args: []string{"pokeman", "get"}, code: 2},
+		{args: []string{"cache", "clear", "--help"}},
+		{args: []string{"cache", "show"}, code: 1},
+	} {
+		config.Out.(*bytes.Buffer).Reset()
+		stderr.Reset()
+
+		if code := runCLI(context.Background(), config, &stderr, test.args); code != test.code {
+			t.Errorf("%v: expected exit code %d but got %d: %s", test.args, test.code, code, stderr.String())
+		}
+		if out := output(config); out != test.out {
+			t.Errorf("%v: expected:\n%s\nbut got:\n%s", test.args, test.out, out)
+		}
+		if test.code == 2 && !strings.Contains(stderr.String(), "Usage: pokedex") {
+			t.Errorf("%v: expected the usage on stderr but got %q", test.args, stderr.String())
+		}
+	}
+}
+
+func TestRunCLICache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex.db")
+	var stderr bytes.Buffer
+
+	// Every run is a process of its own, with the database opened again
+	run := func(args ...string) string {
+		t.Helper()
+
+		db, err := pokestore.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		config := newSessionConfig(t, "")
+		config.Storage = db
+		config.Cache = db.Cache(time.Minute)
+		if code := runCLI(context.Background(), config, &stderr, args); code != 0 {
+			t.Fatalf("%v: expected exit code 0 but got %d: %s", args, code, stderr.String())
+		}
+		return output(config)
+	}
+
+	run("area", "list", "--limit", "2")
+	run("area", "list", "--page", "2", "--limit", "2")
+	if out := run("cache", "show"); out != "2 responses cached\n" {
+		t.Errorf("expected the two pages to be cached but got %q", out)
+	}
+	if out := run("cache", "clear"); out != "Threw out 2 cached responses and the name index\n" {
+		t.Errorf("expected the two pages to be thrown out but got %q", out)
+	}
+	if out := run("cache", "show"); out != "0 responses cached\n" {
+		t.Errorf("expected an empty cache but got %q", out)
+	}
+}
+
+func TestFlagWords(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&spriteValue{}, "sprite", "")
+	pokedexFlags(flags, &pokehelp.PokedexFilter{})
+
+	arguments, err := parseInterspersed(flags, []string{"pikachu", "--sprite", "--sort", "level", "--", "--min-level"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"pikachu", "--min-level"}; !reflect.DeepEqual(arguments, want) {
+		t.Errorf("expected arguments %v but got %v", want, arguments)
+	}
+	if want, got := []string{"--sort=level", "--sprite"}, flagWords(flags); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+
+	words, sprite, variant := spriteFlag([]string{"pikachu", "--sprite=shiny"})
+	if !reflect.DeepEqual(words, []string{"pikachu"}) || !sprite || variant != "shiny" {
+		t.Errorf("expected --sprite=shiny to be understood by inspect but got %v %v %q", words, sprite, variant)
+	}
+}
@@ -1,200 +1,278 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"math/rand"
+	"log/slog"
 	"os"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/munanadi/pokedex/pokehelp"
+	"github.com/munanadi/pokedex/pokelang"
+	"github.com/munanadi/pokedex/pokesprite"
 )
 
+// errExit is returned by CommandExit to stop the REPL
+var errExit = errors.New("exit")
+
 func CommandExit(config *pokehelp.RequestConfig, args ...[]string) error {
-	os.Exit(0)
-	return errors.New("something went wrong in exit")
+	return errExit
 }
 
 func CommandHelp(config *pokehelp.RequestConfig, args ...[]string) error {
 	commands := getCommands()
-	fmt.Printf(`Welcome to Pokedex
-  Usage:
-`)
+	fmt.Fprintln(config.Out, config.Msg("help.welcome"))
+	fmt.Fprintln(config.Out, config.Msg("help.usage"))
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	for _, v := range commands {
-		fmt.Printf("\t%v: %v\n", v.name, v.description)
+	for _, name := range names {
+		fmt.Fprintf(config.Out, "\t%v: %v\n", name, config.Msg("desc."+name))
 	}
 
-	return errors.New("something went wrong in help")
+	return nil
 }
 
-// commandMap will display 20 location areas in the world,
-// subsequent calls should fetch the next 20 locations
+// CommandMap will display a page of location areas in the world,
+// subsequent calls should fetch the next page. Takes `--page N`,
+// `--limit N` and `first`/`last` to jump around.
 func CommandMap(config *pokehelp.RequestConfig, args ...[]string) error {
-	// https://pokeapi.co/api/v2/location/{id or name}/ API to hit.
-
-	// Check if next exists and then make a call to that.
-	// Start at 0 always
-	url := ""
-	if config.Next == nil {
-		fmt.Println("starting from first")
-		url = "https://pokeapi.co/api/v2/location-area/?offset=0&limit=20"
-	} else {
-		url = *config.Next
+	pager := config.Pager
+
+	flags := flag.NewFlagSet("map", flag.ContinueOnError)
+	flags.SetOutput(config.Out)
+	page, limit := mapFlags(flags)
+	if err := flags.Parse(args[0]); err != nil {
+		return nil
 	}
 
-	var data []byte
-	// Check in cache
-	if v, ok := config.Cache.Get(url); !ok {
-		fmt.Println("not in cache, fetching..")
-		data, _ = pokehelp.GetBodyFromUrl(url, config)
-		config.Cache.Add(url, data)
-	} else {
-		fmt.Println("found in cache..")
-		data = v
+	if *limit < 0 || *page < 0 {
+		fmt.Fprintln(config.Out, config.Msg("map.bad_flag"))
+		return nil
+	}
+	if *limit > 0 {
+		pager.SetLimit(*limit)
 	}
 
-	var locations *pokehelp.PokedexLocations
-	err := json.Unmarshal(data, &locations)
-	if err != nil {
-		log.Fatalln("unmarshalling body data failed")
+	if (*page > 0 || flags.Arg(0) == "last") && pager.Count == 0 {
+		// Don't know how many there are yet, the first page will tell
+		if _, err := fetchLocationPage(config); err != nil {
+			return err
+		}
 	}
 
-	var next, previous *string = &locations.Next, &locations.Previous
+	// Where to go back to when the page asked for isn't there
+	offset := pager.Offset
 
-	config.Next = next
-	if len(*previous) == 0 {
-		fmt.Println("prev set as nil")
-		config.Prev = nil
-	} else {
-		fmt.Println("prev set as ", *previous)
-		config.Prev = previous
+	switch {
+	case *page > 0:
+		pager.GoTo(*page)
+	case flags.Arg(0) == "first":
+		pager.GoTo(1)
+	case flags.Arg(0) == "last":
+		pager.GoTo(pager.Pages())
+	case *limit > 0 && pager.Shown:
+		// Just resizing, stay where we are
+	default:
+		if !pager.Next() {
+			fmt.Fprintln(config.Out, config.Msg("map.last_page"))
+			return nil
+		}
 	}
 
-	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+	if pager.Shown && pager.Offset >= pager.Count {
+		fmt.Fprintln(config.Out, config.Msg("map.no_page", pager.Page(), pager.Pages()))
+		pager.Offset = offset
+		return nil
+	}
+
+	locations, err := fetchLocationPage(config)
+	if err != nil {
+		return err
 	}
+	printLocationPage(config, locations)
 
-	return errors.New("something went wrong in map")
+	return nil
 }
 
-// commandMapb will go back 20 location areas in the world,
-// a mehtod to go back, if you're on the first page, prints error.
+// CommandMapb will go back a page of location areas in the world,
+// if you're on the first page, prints error.
 func CommandMapb(config *pokehelp.RequestConfig, args ...[]string) error {
-	// Checking is previous is nil or empty string
-	if config.Prev == nil {
-		fmt.Println("you are on the first page, can't go back, try going forward using `map`")
-	} else {
-		url := *config.Prev
-		var locations *pokehelp.PokedexLocations
+	if !config.Pager.Prev() {
+		fmt.Fprintln(config.Out, config.Msg("mapb.first_page"))
+		return nil
+	}
+
+	locations, err := fetchLocationPage(config)
+	if err != nil {
+		return err
+	}
+	printLocationPage(config, locations)
 
-		fmt.Println(url, " is the url for prev")
+	return nil
+}
 
-		var data []byte
-		// Check in cache
-		if v, ok := config.Cache.Get(url); !ok {
-			fmt.Println("not in cache, fetching..")
-			data, _ = pokehelp.GetBodyFromUrl(url, config)
-			config.Cache.Add(url, data)
-		} else {
-			fmt.Println("found in cache..")
-			data = v
-		}
+// fetchLocationPage gets the location areas on the pager's current page
+// and updates the pager with the total count
+func fetchLocationPage(config *pokehelp.RequestConfig) (*pokehelp.PokedexLocations, error) {
+	url := config.Pager.URL(config.Endpoint("location-area"))
 
-		err := json.Unmarshal(data, &locations)
-		if err != nil {
-			log.Fatalln("unmarshalling body data failed")
-		}
+	var locations *pokehelp.PokedexLocations
+	if err := pokehelp.FetchInto(url, config, &locations); err != nil {
+		return nil, err
+	}
 
-		var next, previous string = locations.Next, locations.Previous
+	config.Pager.Count = locations.Count
+	config.Pager.Shown = true
 
-		config.Next = &next
-		if len(previous) == 0 {
-			config.Prev = nil
-		} else {
-			config.Prev = &previous
-		}
+	return locations, nil
+}
 
-		for _, location := range locations.Results {
-			fmt.Println(location.Name)
-		}
+func printLocationPage(config *pokehelp.RequestConfig, locations *pokehelp.PokedexLocations) {
+	urls, slugs := []string{}, []string{}
+	for _, location := range locations.Results {
+		urls, slugs = append(urls, location.URL), append(slugs, location.Name)
+	}
+	for _, name := range pokehelp.LocalizedNamesFromUrls(urls, slugs, config) {
+		fmt.Fprintln(config.Out, name)
 	}
 
-	return errors.New("something went wrong in mapb")
+	fmt.Fprintln(config.Out, config.Msg("map.status", config.Pager.Page(), config.Pager.Pages(), locations.Count))
 }
 
 func CommandExplore(config *pokehelp.RequestConfig, args ...[]string) error {
 	cityAreaToExplore := strings.Join(args[0], "")
-	fmt.Printf("Exploring %s...\n", cityAreaToExplore)
 
-	baseUrl := "https://pokeapi.co/api/v2/location-area/"
+	baseUrl := config.Endpoint("location-area")
 	url := baseUrl + cityAreaToExplore
 
 	var res *pokehelp.PokedexLocationExplore
-
-	var data []byte
-	// Check in cache
-	if v, ok := config.Cache.Get(url); !ok {
-		fmt.Println("not in cache, fetching..")
-		data, _ = pokehelp.GetBodyFromUrl(url, config)
-		config.Cache.Add(url, data)
-	} else {
-		fmt.Println("found in cache..")
-		data = v
+	if err := pokehelp.FetchInto(url, config, &res); err != nil {
+		return reportUnknown(config, pokehelp.KindLocationArea, cityAreaToExplore, err)
 	}
+	// An area explored by id is kept by its name
+	cityAreaToExplore = res.Name
 
-	json.Unmarshal(data, &res)
+	config.Area = cityAreaToExplore
+	fmt.Fprintln(config.Out, config.Msg("explore.exploring", res.Names.Localized(config, cityAreaToExplore)))
 
-	fmt.Println("Found Pokemon:")
+	fmt.Fprintln(config.Out, config.Msg("explore.found"))
 	for _, v := range res.PokemonEncounters {
-		fmt.Printf("- %s\n", v.Pokemon.Name)
+		if config.Game != nil && !v.FoundIn(config.Game.Name) {
+			continue
+		}
+		config.MarkSeen(cityAreaToExplore, v.Pokemon.Name)
+
+		name := localizedPokemonName(v.Pokemon, config)
+		conditions := v.Conditions(gameName(config))
+		if len(conditions) == 0 {
+			fmt.Fprintf(config.Out, "- %s\n", name)
+			continue
+		}
+
+		// Like "hoothoot (time-night)" or "(swarm-yes, time-day or ...)"
+		sets := make([]string, len(conditions))
+		for i, set := range conditions {
+			names := make([]string, len(set))
+			for j, value := range set {
+				names[j] = value.LocalizedName(config)
+			}
+			sets[i] = strings.Join(names, ", ")
+		}
+		fmt.Fprintf(config.Out, "- %s (%s)\n", name, strings.Join(sets, config.Msg("explore.or")))
 	}
 
-	return errors.New("something went wrong in explore")
+	return nil
 }
 
 func CommandCatch(config *pokehelp.RequestConfig, args ...[]string) error {
 	pokemonName := strings.Join(args[0], "")
-	fmt.Printf("Throwing a Pokeball at %s...\n", pokemonName)
 
-	baseUrl := "https://pokeapi.co/api/v2/pokemon/"
+	baseUrl := config.Endpoint("pokemon")
 	url := baseUrl + pokemonName
 
-	var res *pokehelp.Pokemon
-
-	var data []byte
-	// Check in cache
-	if v, ok := config.Cache.Get(url); !ok {
-		fmt.Println("not in cache, fetching..")
-		data, _ = pokehelp.GetBodyFromUrl(url, config)
-		config.Cache.Add(url, data)
-	} else {
-		fmt.Println("found in cache..")
-		data = v
+	var res *pokehelp.PokemonSummary
+	if err := pokehelp.FetchInto(url, config, &res); err != nil {
+		return reportUnknown(config, pokehelp.KindPokemon, pokemonName, err)
 	}
+	// A pokemon caught by id goes in the pokedex by its name
+	pokemonName = res.Name
 
-	json.Unmarshal(data, &res)
+	displayName := pokehelp.LocalizedNameFromUrl(res.Species.URL, pokemonName, config)
+	fmt.Fprintln(config.Out, config.Msg("catch.throwing", displayName))
 
 	// Try to catch it
 	// TODO: 50/50 now, later try to include the experince in this equation
-	if rand.Intn(10) > 5 {
-		fmt.Printf("%s escaped!\n", pokemonName)
+	if config.Rand.Intn(10) > 5 {
+		fmt.Fprintln(config.Out, config.Msg("catch.escaped", displayName))
 	} else {
-		fmt.Printf("%s was caught!\n", pokemonName)
-		config.Pokedex[pokemonName] = *res
-		fmt.Println("You may now inspect it with the inspect command.")
+		fmt.Fprintln(config.Out, config.Msg("catch.caught", displayName))
+		config.Pokedex[pokemonName] = pokehelp.CaughtPokemon{
+			PokemonSummary: *res,
+			Level:          catchLevel(config, pokemonName),
+			CaughtAt:       time.Now().UTC().Truncate(time.Second),
+			CaughtIn:       config.Area,
+			Game:           gameName(config),
+		}
+		// Whatever it was holding goes in the bag
+		for _, held := range res.HeldItems {
+			if config.Rand.Intn(100) < held.Rarity(gameName(config)) {
+				config.AddItem(held.Item.Name, 1)
+				fmt.Fprintln(config.Out, config.Msg("catch.held_item", displayName, held.Item.LocalizedName(config)))
+			}
+		}
+		fmt.Fprintln(config.Out, config.Msg("catch.hint"))
+	}
+
+	return nil
+}
+
+// defaultLevel is what a pokemon caught outside of an explored area is at
+const defaultLevel = 5
+
+// catchLevel picks a level in the range the pokemon is met at in the
+// area explored last, in the picked game if there is one
+func catchLevel(config *pokehelp.RequestConfig, pokemonName string) int {
+	if config.Area == "" {
+		return defaultLevel
 	}
 
-	return errors.New("something went wrong in catch")
+	var area *pokehelp.PokedexLocationExplore
+	if err := pokehelp.FetchInto(config.Endpoint(pokehelp.KindLocationArea)+config.Area, config, &area); err != nil {
+		return defaultLevel
+	}
+	for _, v := range area.PokemonEncounters {
+		if v.Pokemon.Name != pokemonName {
+			continue
+		}
+		if low, high := v.Levels(gameName(config)); low > 0 {
+			return low + config.Rand.Intn(high-low+1)
+		}
+	}
+	return defaultLevel
 }
 
+// CommandInspect shows what is known about a caught pokemon, and draws
+// its sprite with `--sprite [VARIANT]`
 func CommandInspect(config *pokehelp.RequestConfig, args ...[]string) error {
-	pokemonName := strings.Join(args[0], "")
+	words, sprite, variant := spriteFlag(args[0])
+	if variant != "" && !slices.Contains(pokehelp.SpriteVariants, variant) {
+		fmt.Fprintln(config.Out, config.Msg("inspect.bad_sprite", variant, strings.Join(pokehelp.SpriteVariants, ", ")))
+		return nil
+	}
+	pokemonName := strings.Join(words, "")
 
 	if _, ok := config.Pokedex[pokemonName]; !ok {
-		fmt.Println("you have not caught that pokemon")
+		fmt.Fprintln(config.Out, config.Msg("inspect.not_caught"))
+		suggestCaught(config, pokemonName)
 		return nil
 	}
 
@@ -202,20 +280,965 @@ func CommandInspect(config *pokehelp.RequestConfig, args ...[]string) error {
 
 	name, height, weight, _, types := pD.Name, pD.Height, pD.Weight, pD.Stats, pD.Types
 
-	fmt.Printf("Name: %s\nHeight: %d\nWeight: %d", name, height, weight)
-	fmt.Printf("\nTypes\n")
+	fmt.Fprintln(config.Out, config.Msg("inspect.name", pokehelp.LocalizedNameFromUrl(pD.Species.URL, name, config)))
+	if pD.Nickname != "" {
+		fmt.Fprintln(config.Out, config.Msg("inspect.nickname", pD.Nickname))
+	}
+	fmt.Fprintln(config.Out, config.Msg("inspect.level", pD.Level))
+	fmt.Fprintln(config.Out, config.Msg("inspect.height", height))
+	fmt.Fprintln(config.Out, config.Msg("inspect.weight", weight))
+	fmt.Fprintln(config.Out, config.Msg("inspect.types"))
 	for _, v := range types {
-		fmt.Println("\t - ", v.Type.Name)
+		fmt.Fprintln(config.Out, "\t - ", v.Type.LocalizedName(config))
+	}
+	heldItems := false
+	for _, v := range pD.HeldItems {
+		rarities := v.Rarities(gameName(config))
+		if len(rarities) == 0 {
+			continue
+		}
+
+		if !heldItems {
+			fmt.Fprintln(config.Out, config.Msg("inspect.held_items"))
+			heldItems = true
+		}
+		chances := make([]string, len(rarities))
+		for i, r := range rarities {
+			chances[i] = config.Msg("inspect.rarity", r.Rarity, strings.Join(r.Versions, ", "))
+		}
+		fmt.Fprintln(config.Out, "\t - ", v.Item.LocalizedName(config), strings.Join(chances, "; "))
+	}
+	fmt.Fprintln(config.Out, config.Msg("inspect.abilities"))
+	for _, v := range pD.Abilities {
+		if v.IsHidden {
+			fmt.Fprintln(config.Out, "\t - ", v.Ability.LocalizedName(config), config.Msg("inspect.hidden"))
+		} else {
+			fmt.Fprintln(config.Out, "\t - ", v.Ability.LocalizedName(config))
+		}
+	}
+
+	if config.Game != nil {
+		sprites, err := pD.Sprites(config)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(config.Out, config.Msg("inspect.sprite", sprites.SpriteFor(*config.Game)))
+	}
+
+	if sprite {
+		return drawSprite(config, pD.PokemonSummary, variant)
+	}
+
+	return nil
+}
+
+// spriteFlag takes `--sprite [VARIANT]` or `--sprite=VARIANT` out of the
+// words given to inspect, wherever they are
+func spriteFlag(words []string) (rest []string, sprite bool, variant string) {
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "--sprite":
+			sprite = true
+			if i+1 < len(words) && slices.Contains(pokehelp.SpriteVariants, words[i+1]) {
+				variant = words[i+1]
+				i++
+			}
+		case strings.HasPrefix(word, "--sprite="):
+			sprite = true
+			variant = strings.TrimPrefix(word, "--sprite=")
+		default:
+			rest = append(rest, word)
+		}
+	}
+	return rest, sprite, variant
+}
+
+// drawSprite downloads the sprite through the cache and draws it in the
+// terminal, the one for the picked game if no variant is asked for
+func drawSprite(config *pokehelp.RequestConfig, pokemon pokehelp.PokemonSummary, variant string) error {
+	mode := pokesprite.DetectMode(os.Getenv)
+	switch color := config.Settings.String("color"); color {
+	case "never":
+		fmt.Fprintln(config.Out, config.Msg("inspect.no_color"))
+		return nil
+	case "auto":
+	default:
+		mode, _ = pokesprite.ParseMode(color)
+	}
+
+	sprites, err := pokemon.Sprites(config)
+	if err != nil {
+		return err
+	}
+
+	var url string
+	switch {
+	case variant != "":
+		url = sprites.Variant(variant)
+	case config.Game != nil:
+		url = sprites.SpriteFor(*config.Game)
+	default:
+		url = sprites.FrontDefault
+	}
+	if url == "" {
+		fmt.Fprintln(config.Out, config.Msg("inspect.no_sprite"))
+		return nil
+	}
+
+	data, err := pokehelp.GetCachedBodyFromUrl(url, config)
+	if err != nil {
+		return err
 	}
 
-	return errors.New("something went wrong in inspect")
+	return pokesprite.Render(config.Out, data, mode)
+}
+
+// mapFlags are the flags of map, shared with `pokedex area list`
+func mapFlags(flags *flag.FlagSet) (page *int, limit *int) {
+	page = flags.Int("page", 0, "page number to jump to")
+	limit = flags.Int("limit", 0, "number of locations per page")
+	return page, limit
+}
+
+// CommandPokedex lists the caught pokemon. `--type T`, `--min-level N` and
+// `--max-level N` filter them, `--sort name|level|caught` orders them.
+func CommandInventory(config *pokehelp.RequestConfig, args ...[]string) error {
+	if len(config.Inventory) == 0 {
+		fmt.Fprintln(config.Out, config.Msg("inventory.empty"))
+		return nil
+	}
+
+	items := make([]string, 0, len(config.Inventory))
+	for item := range config.Inventory {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+
+	fmt.Fprintln(config.Out, config.Msg("inventory.header"))
+	for _, item := range items {
+		ref := pokehelp.NamedAPIResource[pokehelp.Item]{Name: item, URL: config.Endpoint(pokehelp.KindItem) + item}
+		fmt.Fprintf(config.Out, "\t - %s x%d\n", ref.LocalizedName(config), config.Inventory[item])
+	}
+
+	return nil
 }
 
 func CommandPokedex(config *pokehelp.RequestConfig, args ...[]string) error {
-	fmt.Println("Your Pokedex:")
-	for _, pokemon := range config.Pokedex {
-		fmt.Println("- ", pokemon.Name)
+	var filter pokehelp.PokedexFilter
+	flags := flag.NewFlagSet("pokedex", flag.ContinueOnError)
+	flags.SetOutput(config.Out)
+	pokedexFlags(flags, &filter)
+	if err := flags.Parse(args[0]); err != nil {
+		return nil
+	}
+	if !slices.Contains(pokehelp.PokedexSorts, filter.Sort) {
+		fmt.Fprintln(config.Out, config.Msg("pokedex.bad_sort", strings.Join(pokehelp.PokedexSorts, ", ")))
+		return nil
+	}
+
+	caught, err := config.QueryCaught(filter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(config.Out, config.Msg("pokedex.header"))
+	for _, pokemon := range caught {
+		fmt.Fprintln(config.Out, "- ", pokehelp.LocalizedNameFromUrl(pokemon.Species.URL, pokemon.Name, config))
+	}
+	fmt.Fprintln(config.Out, config.Msg("pokedex.seen", config.SeenCount(), len(config.Pokedex)))
+
+	return nil
+}
+
+// pokedexFlags are the filters of pokedex, shared with `pokedex pokemon
+// list`
+func pokedexFlags(flags *flag.FlagSet, filter *pokehelp.PokedexFilter) {
+	flags.StringVar(&filter.Type, "type", "", "only list pokemon of this type")
+	flags.IntVar(&filter.MinLevel, "min-level", 0, "only list pokemon at this level or higher")
+	flags.IntVar(&filter.MaxLevel, "max-level", 0, "only list pokemon at this level or lower")
+	flags.StringVar(&filter.Sort, "sort", pokehelp.SortByName, "order by "+strings.Join(pokehelp.PokedexSorts, ", "))
+}
+
+// CommandMoves lists the moves a caught pokemon can learn
+func CommandMoves(config *pokehelp.RequestConfig, args ...[]string) error {
+	pokemonName := strings.Join(args[0], "")
+
+	pD, ok := config.Pokedex[pokemonName]
+	if !ok {
+		fmt.Fprintln(config.Out, config.Msg("inspect.not_caught"))
+		suggestCaught(config, pokemonName)
+		return nil
+	}
+
+	moves, err := pD.Moves(config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(config.Out, config.Msg("moves.header", pokehelp.LocalizedNameFromUrl(pD.Species.URL, pD.Name, config)))
+	for _, v := range moves {
+		moveName := v.Move.LocalizedName(config)
+
+		if config.Game == nil {
+			fmt.Fprintln(config.Out, "\t - ", moveName)
+			continue
+		}
+
+		// Only the moves learnable in the chosen game, with how they're learnt there
+		for _, detail := range v.VersionGroupDetails {
+			if detail.VersionGroup.Name != config.Game.VersionGroup {
+				continue
+			}
+			if detail.MoveLearnMethod.Name == "level-up" {
+				fmt.Fprintln(config.Out, "\t - ", moveName, config.Msg("moves.level", detail.LevelLearnedAt))
+			} else {
+				fmt.Fprintln(config.Out, "\t - ", moveName, "("+detail.MoveLearnMethod.Name+")")
+			}
+		}
+	}
+
+	return nil
+}
+
+// CommandLang shows the current language, or switches to the one given
+func CommandLang(config *pokehelp.RequestConfig, args ...[]string) error {
+	lang := strings.Join(args[0], "")
+
+	if lang == "" {
+		fmt.Fprintln(config.Out, config.Msg("lang.current", config.Lang))
+		return nil
+	}
+
+	if !pokelang.IsSupported(lang) {
+		fmt.Fprintln(config.Out, config.Msg("lang.unsupported", lang, strings.Join(pokelang.Supported(), ", ")))
+		return nil
+	}
+
+	config.Lang = lang
+	fmt.Fprintln(config.Out, config.Msg("lang.set", lang))
+
+	return nil
+}
+
+// CommandGame shows the current game, or sets the game that explore,
+// moves and inspect are filtered to. `game all` clears it.
+func CommandGame(config *pokehelp.RequestConfig, args ...[]string) error {
+	game := strings.Join(args[0], "")
+
+	switch game {
+	case "":
+		if config.Game == nil {
+			fmt.Fprintln(config.Out, config.Msg("game.none"))
+		} else {
+			fmt.Fprintln(config.Out, config.Msg("game.current", config.Game.Name))
+		}
+		return nil
+	case "all":
+		config.Game = nil
+		fmt.Fprintln(config.Out, config.Msg("game.none"))
+		return nil
+	}
+
+	version, ok := pokehelp.LookupGameVersion(game)
+	if !ok {
+		fmt.Fprintln(config.Out, config.Msg("game.unknown", game, strings.Join(pokehelp.GameVersionNames(), ", ")))
+		return nil
+	}
+
+	config.Game = &version
+	fmt.Fprintln(config.Out, config.Msg("game.set", version.Name, version.VersionGroup, version.Generation))
+
+	return nil
+}
+
+// reportUnknown prints the closest names from the name index when the
+// API doesn't know name, any other error is returned as it is. The index
+// only makes suggestions, without it there just aren't any.
+func reportUnknown(config *pokehelp.RequestConfig, kind string, name string, err error) error {
+	if !errors.Is(err, pokehelp.ErrNotFound) {
+		return err
+	}
+
+	fmt.Fprintln(config.Out, config.Msg("names.unknown", name, kind))
+	index, err := pokehelp.GetNameIndex(config)
+	if err != nil {
+		slog.Warn("building the name index failed", "err", err)
+		return nil
+	}
+	if suggestions := index.Suggest(kind, name, 3); len(suggestions) > 0 {
+		fmt.Fprintln(config.Out, config.Msg("names.suggest", strings.Join(suggestions, ", ")))
+	}
+
+	return nil
+}
+
+// suggestCaught prints the caught pokemon with names close to name
+func suggestCaught(config *pokehelp.RequestConfig, name string) {
+	if suggestions := pokehelp.SuggestFrom(caughtNames(config), name, 3); len(suggestions) > 0 {
+		fmt.Fprintln(config.Out, config.Msg("names.suggest", strings.Join(suggestions, ", ")))
+	}
+}
+
+func caughtNames(config *pokehelp.RequestConfig) []string {
+	names := make([]string, 0, len(config.Pokedex))
+	for name := range config.Pokedex {
+		names = append(names, name)
+	}
+	return names
+}
+
+// gameName is the version picked with `game`, or empty for all of them
+func gameName(config *pokehelp.RequestConfig) string {
+	if config.Game == nil {
+		return ""
+	}
+	return config.Game.Name
+}
+
+// localizedPokemonName finds the name of a pokemon in the configured
+// language, the names live on its species so that is one more lookup
+func localizedPokemonName(pokemon pokehelp.NamedAPIResource[pokehelp.Pokemon], config *pokehelp.RequestConfig) string {
+	if config.Lang == "" || config.Lang == pokelang.DefaultLang {
+		return pokemon.Name
+	}
+
+	// The summary is enough to get to the species
+	res, err := pokehelp.NamedAPIResource[pokehelp.PokemonSummary](pokemon).Resolve(config.Context(), config)
+	if err != nil {
+		return pokemon.Name
+	}
+
+	return pokehelp.LocalizedNameFromUrl(res.Species.URL, pokemon.Name, config)
+}
+
+// CommandRegion lists all the regions with `region list`,
+// or picks the region `locations` will show with `region <name>`
+func CommandRegion(config *pokehelp.RequestConfig, args ...[]string) error {
+	regionName := strings.Join(args[0], "")
+
+	url := config.Endpoint("region") + "?offset=0&limit=100"
+	var regions *pokehelp.ResourceList
+	if err := pokehelp.FetchInto(url, config, &regions); err != nil {
+		return err
+	}
+
+	if regionName == "" || regionName == "list" {
+		fmt.Fprintln(config.Out, config.Msg("region.header"))
+		for _, v := range regions.Results {
+			fmt.Fprintf(config.Out, "- %s\n", v.LocalizedName(config))
+		}
+		return nil
+	}
+
+	for _, v := range regions.Results {
+		if v.Name != regionName {
+			continue
+		}
+
+		var region *pokehelp.Region
+		if err := pokehelp.FetchInto(v.URL, config, &region); err != nil {
+			return err
+		}
+
+		config.Region = region.Name
+		fmt.Fprintln(config.Out, config.Msg("region.set", region.Names.Localized(config, region.Name), len(region.Locations)))
+		return nil
+	}
+
+	fmt.Fprintln(config.Out, config.Msg("region.unknown", regionName))
+	return nil
+}
+
+// CommandLocations lists the locations in the region picked with `region`
+func CommandLocations(config *pokehelp.RequestConfig, args ...[]string) error {
+	if config.Region == "" {
+		fmt.Fprintln(config.Out, config.Msg("region.none"))
+		return nil
+	}
+
+	url := config.Endpoint("region") + config.Region
+	var region *pokehelp.Region
+	if err := pokehelp.FetchInto(url, config, &region); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(config.Out, config.Msg("locations.header", region.Names.Localized(config, region.Name)))
+	for _, v := range region.Locations {
+		fmt.Fprintf(config.Out, "- %s\n", v.LocalizedName(config))
+	}
+
+	return nil
+}
+
+// CommandAreas lists the location areas of a location, the names
+// printed here are what `explore` takes
+func CommandAreas(config *pokehelp.RequestConfig, args ...[]string) error {
+	locationName := strings.Join(args[0], "")
+	if locationName == "" {
+		fmt.Fprintln(config.Out, config.Msg("areas.usage"))
+		return nil
+	}
+
+	url := config.Endpoint("location") + locationName
+	var location *pokehelp.Location
+	if err := pokehelp.FetchInto(url, config, &location); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(config.Out, config.Msg("areas.header", location.Names.Localized(config, location.Name), location.Region.Name))
+	for _, v := range location.Areas {
+		fmt.Fprintf(config.Out, "- %s\n", v.Name)
+	}
+
+	return nil
+}
+
+// CommandSnapshot manages the local copy of the API used by --offline,
+// `snapshot download [KIND...]` fetches it, `snapshot import <DIR>` copies
+// it from a directory and `snapshot` on its own shows what is in it
+func CommandSnapshot(config *pokehelp.RequestConfig, args ...[]string) error {
+	words := args[0]
+	if len(words) == 0 || words[0] == "" {
+		fmt.Fprintln(config.Out, config.Msg("snapshot.header", config.Store.Dir))
+		for _, kind := range pokehelp.SnapshotKinds {
+			fmt.Fprintf(config.Out, "- %s: %d\n", kind, config.Store.Count(kind))
+		}
+		return nil
+	}
+
+	switch words[0] {
+	case "download":
+		if config.Offline {
+			fmt.Fprintln(config.Out, config.Msg("snapshot.offline"))
+			return nil
+		}
+
+		kinds := words[1:]
+		if len(kinds) == 0 {
+			kinds = pokehelp.SnapshotKinds
+		}
+
+		progress := func(kind string, done, total int) {
+			if done%50 == 0 || done == total {
+				fmt.Fprintln(config.Out, config.Msg("snapshot.progress", kind, done, total))
+			}
+		}
+		if err := pokehelp.DownloadSnapshot(config.Context(), config.Client, config.Store, config.Endpoint, kinds, progress); err != nil {
+			return err
+		}
+		fmt.Fprintln(config.Out, config.Msg("snapshot.done"))
+	case "import":
+		if len(words) < 2 {
+			fmt.Fprintln(config.Out, config.Msg("snapshot.usage"))
+			return nil
+		}
+
+		imported, err := pokehelp.ImportSnapshot(config.Store, words[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(config.Out, config.Msg("snapshot.imported", imported))
+	default:
+		fmt.Fprintln(config.Out, config.Msg("snapshot.usage"))
+	}
+
+	return nil
+}
+
+// CommandExport writes the Pokedex to a file, as json, csv or a markdown
+// table. The format is taken from the extension without --format, and
+// `-` writes to the terminal.
+func CommandExport(config *pokehelp.RequestConfig, args ...[]string) error {
+	file, format, ok := exportFlags(config, "export", args[0], nil)
+	if !ok {
+		return nil
+	}
+
+	names := caughtNames(config)
+	sort.Strings(names)
+	entries := make([]pokehelp.PokedexEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, pokehelp.NewPokedexEntry(config.Pokedex[name]))
+	}
+
+	if file == "-" {
+		return pokehelp.Export(config.Out, format, entries)
+	}
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := pokehelp.Export(out, format, entries); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintln(config.Out, config.Msg("export.done", len(entries), file))
+	return nil
+}
+
+// CommandImport adds the pokemon in an exported Pokedex to this one. Rows
+// that don't validate, aren't a pokemon the API knows or repeat one are
+// reported and skipped, and so are pokemon already in the pokedex unless
+// `--replace` is given.
+func CommandImport(config *pokehelp.RequestConfig, args ...[]string) error {
+	var replace bool
+	file, format, ok := exportFlags(config, "import", args[0], &replace)
+	if !ok {
+		return nil
+	}
+
+	// - reads what is piped in, like export writes to the terminal
+	in := config.In
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	entries, bad, err := pokehelp.Import(in, format)
+	if err != nil {
+		return err
+	}
+
+	imported := 0
+	rows := map[string]int{}
+	for _, entry := range entries {
+		if row, ok := rows[entry.Name]; ok {
+			bad = append(bad, pokehelp.ImportError{Row: entry.Row, Err: fmt.Errorf("%s is in row %d already", entry.Name, row)})
+			continue
+		}
+		rows[entry.Name] = entry.Row
+		if !replace && hasCaught(config, entry.Name) {
+			bad = append(bad, pokehelp.ImportError{Row: entry.Row, Err: fmt.Errorf("%s is already in your pokedex, --replace overwrites it", entry.Name)})
+			continue
+		}
+
+		var summary *pokehelp.PokemonSummary
+		if err := pokehelp.FetchInto(config.Endpoint(pokehelp.KindPokemon)+entry.Name, config, &summary); err != nil {
+			if config.Context().Err() != nil {
+				return err
+			}
+			bad = append(bad, pokehelp.ImportError{Row: entry.Row, Err: err})
+			continue
+		}
+		if id := summary.SpeciesID(); id != entry.SpeciesID {
+			bad = append(bad, pokehelp.ImportError{Row: entry.Row, Err: fmt.Errorf("species_id %d doesn't match %s, that is %d", entry.SpeciesID, entry.Name, id)})
+			continue
+		}
+
+		config.Pokedex[entry.Name] = entry.Caught(*summary)
+		imported++
+	}
+
+	sort.Slice(bad, func(i, j int) bool { return bad[i].Row < bad[j].Row })
+	for _, e := range bad {
+		fmt.Fprintln(config.Out, config.Msg("import.bad_row", e.Row, e.Err))
+	}
+	fmt.Fprintln(config.Out, config.Msg("import.done", imported, len(bad)))
+	return nil
+}
+
+// exportFlags parses `[--format FORMAT] <FILE>` for export and import,
+// and `--replace` into replace when it isn't nil
+func exportFlags(config *pokehelp.RequestConfig, name string, words []string, replace *bool) (file string, format string, ok bool) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(config.Out)
+	formatFlag(flags, &format)
+	if replace != nil {
+		replaceFlag(flags, replace)
+	}
+	if err := flags.Parse(words); err != nil {
+		return "", "", false
+	}
+
+	if flags.NArg() != 1 || flags.Arg(0) == "" {
+		fmt.Fprintln(config.Out, config.Msg(name+".usage"))
+		return "", "", false
+	}
+	file = flags.Arg(0)
+
+	if format == "" {
+		format = pokehelp.FormatFromPath(file)
+	}
+	if format == "" {
+		format = config.Settings.String("export_format")
+	}
+	if !slices.Contains(pokehelp.ExportFormats, format) {
+		fmt.Fprintln(config.Out, config.Msg("export.bad_format", strings.Join(pokehelp.ExportFormats, ", ")))
+		return "", "", false
+	}
+	return file, format, true
+}
+
+// formatFlag is the --format of export and import
+func formatFlag(flags *flag.FlagSet, format *string) {
+	flags.StringVar(format, "format", "", "one of "+strings.Join(pokehelp.ExportFormats, ", ")+", from the file's extension or the export_format setting if not given")
+}
+
+// CommandTeam manages the team of up to six caught pokemon. `team` lists
+// it, `team add` and `team remove` change it, and `team export [FILE]`
+// and `team import <FILE>` use Pokemon Showdown's paste format.
+func CommandTeam(config *pokehelp.RequestConfig, args ...[]string) error {
+	words := args[0]
+	if len(words) == 0 || words[0] == "" {
+		if len(config.Team) == 0 {
+			fmt.Fprintln(config.Out, config.Msg("team.empty"))
+			return nil
+		}
+		fmt.Fprintln(config.Out, config.Msg("team.header", len(config.Team), pokehelp.TeamSize))
+		for _, name := range config.Team {
+			pokemon := config.Pokedex[name]
+			fmt.Fprintln(config.Out, "- ", pokehelp.LocalizedNameFromUrl(pokemon.Species.URL, pokemon.Name, config), config.Msg("team.level", pokemon.Level))
+		}
+		return nil
+	}
+
+	name := strings.Join(words[1:], "")
+	// File names can have spaces in them
+	file := strings.Join(words[1:], " ")
+	switch words[0] {
+	case "add":
+		if _, ok := config.Pokedex[name]; !ok {
+			fmt.Fprintln(config.Out, config.Msg("inspect.not_caught"))
+			suggestCaught(config, name)
+			return nil
+		}
+		if slices.Contains(config.Team, name) {
+			fmt.Fprintln(config.Out, config.Msg("team.already", name))
+			return nil
+		}
+		if len(config.Team) >= pokehelp.TeamSize {
+			fmt.Fprintln(config.Out, config.Msg("team.full", pokehelp.TeamSize))
+			return nil
+		}
+		config.Team = append(config.Team, name)
+		fmt.Fprintln(config.Out, config.Msg("team.added", name))
+	case "remove":
+		i := slices.Index(config.Team, name)
+		if i < 0 {
+			fmt.Fprintln(config.Out, config.Msg("team.not_in_team", name))
+			return nil
+		}
+		config.Team = slices.Delete(config.Team, i, i+1)
+		fmt.Fprintln(config.Out, config.Msg("team.removed", name))
+	case "export":
+		return exportTeam(config, file)
+	case "import":
+		flags := flag.NewFlagSet("team import", flag.ContinueOnError)
+		flags.SetOutput(config.Out)
+		var replace bool
+		replaceFlag(flags, &replace)
+		if err := flags.Parse(words[1:]); err != nil {
+			return nil
+		}
+		file = strings.Join(flags.Args(), " ")
+		if file == "" {
+			fmt.Fprintln(config.Out, config.Msg("team.usage"))
+			return nil
+		}
+		return importTeam(config, file, replace)
+	default:
+		fmt.Fprintln(config.Out, config.Msg("team.usage"))
+	}
+
+	return nil
+}
+
+// replaceFlag is the --replace of team import and import
+func replaceFlag(flags *flag.FlagSet, replace *bool) {
+	flags.BoolVar(replace, "replace", false, "overwrite pokemon already in the pokedex instead of skipping them")
+}
+
+// exportTeam writes the team as a Showdown paste to file, or to the
+// terminal without one
+func exportTeam(config *pokehelp.RequestConfig, file string) error {
+	versionGroup := ""
+	if config.Game != nil {
+		versionGroup = config.Game.VersionGroup
+	}
+
+	sets := make([]pokehelp.ShowdownSet, 0, len(config.Team))
+	for _, name := range config.Team {
+		pokemon := config.Pokedex[name]
+		moves, err := pokemon.KnownMoves(config, versionGroup)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, pokehelp.NewShowdownSet(pokemon, moves))
+	}
+
+	if file == "" || file == "-" {
+		return pokehelp.WriteShowdown(config.Out, sets)
+	}
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := pokehelp.WriteShowdown(out, sets); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintln(config.Out, config.Msg("export.done", len(sets), file))
+	return nil
+}
+
+// importTeam reads a Showdown paste into caught pokemon and makes them
+// the team. Sets with a species, ability or move the API doesn't have
+// for the pokemon are reported and skipped, and so are pokemon already in
+// the pokedex unless replace is set. A paste without a single good set
+// leaves the team as it was.
+func importTeam(config *pokehelp.RequestConfig, file string, replace bool) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	sets, bad, err := pokehelp.ReadShowdown(in)
+	if err != nil {
+		return err
+	}
+
+	var team []string
+	for _, set := range sets {
+		var pokemon *pokehelp.PokemonSummary
+		if err = pokehelp.FetchInto(config.Endpoint(pokehelp.KindPokemon)+set.Species, config, &pokemon); err == nil {
+			var moves []pokehelp.PokemonMove
+			if moves, err = pokemon.Moves(config); err == nil {
+				err = set.Validate(pokemon, moves)
+			}
+		}
+		switch {
+		case err != nil:
+		case slices.Contains(team, set.Species):
+			err = fmt.Errorf("%s is in the paste more than once", set.Species)
+		case !replace && hasCaught(config, set.Species):
+			err = fmt.Errorf("%s is already in your pokedex, --replace overwrites it", set.Species)
+		case len(team) >= pokehelp.TeamSize:
+			err = fmt.Errorf("a team has %d pokemon at most", pokehelp.TeamSize)
+		}
+		if err != nil {
+			if config.Context().Err() != nil {
+				return err
+			}
+			bad = append(bad, pokehelp.ImportError{Row: set.Row, Err: err})
+			continue
+		}
+
+		caught := set.Caught(*pokemon)
+		caught.CaughtAt = time.Now().UTC().Truncate(time.Second)
+		config.Pokedex[set.Species] = caught
+		team = append(team, set.Species)
+	}
+	if len(team) > 0 {
+		config.Team = team
+	}
+
+	sort.Slice(bad, func(i, j int) bool { return bad[i].Row < bad[j].Row })
+	for _, e := range bad {
+		fmt.Fprintln(config.Out, config.Msg("team.bad_set", e.Row, e.Err))
+	}
+	fmt.Fprintln(config.Out, config.Msg("team.imported", len(team), len(bad)))
+	return nil
+}
+
+func hasCaught(config *pokehelp.RequestConfig, name string) bool {
+	_, ok := config.Pokedex[name]
+	return ok
+}
+
+// CommandProfile manages the save profiles, every one with its own
+// Pokedex, team, location and settings. `profile` shows the one in use,
+// `profile list|new|switch|delete` manage them.
+func CommandProfile(config *pokehelp.RequestConfig, args ...[]string) error {
+	if config.Storage == nil {
+		fmt.Fprintln(config.Out, config.Msg("profile.disabled"))
+		return nil
+	}
+
+	words := args[0]
+	if len(words) == 0 || words[0] == "" {
+		fmt.Fprintln(config.Out, config.Msg("profile.current", config.Profile))
+		return nil
+	}
+
+	name := strings.Join(words[1:], "")
+	if words[0] != "list" && !pokehelp.ValidProfileName(name) {
+		fmt.Fprintln(config.Out, config.Msg("profile.usage"))
+		return nil
+	}
+
+	switch words[0] {
+	case "list":
+		names, err := config.Storage.List()
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(names, config.Profile) {
+			names = append(names, config.Profile)
+			sort.Strings(names)
+		}
+		fmt.Fprintln(config.Out, config.Msg("profile.header"))
+		for _, name := range names {
+			marker := " "
+			if name == config.Profile {
+				marker = "*"
+			}
+			fmt.Fprintf(config.Out, "%s %s\n", marker, name)
+		}
+	case "new":
+		if config.Storage.Exists(name) || name == config.Profile {
+			fmt.Fprintln(config.Out, config.Msg("profile.exists", name))
+			return nil
+		}
+		if err := config.SaveProfile(); err != nil {
+			return err
+		}
+		profile := pokehelp.NewProfile(name)
+		profile.Lang = config.Lang
+		if err := config.Storage.Save(profile); err != nil {
+			return err
+		}
+		config.UseProfile(profile)
+		fmt.Fprintln(config.Out, config.Msg("profile.created", name))
+	case "switch":
+		if name == config.Profile {
+			fmt.Fprintln(config.Out, config.Msg("profile.current", name))
+			return nil
+		}
+		profile, err := config.Storage.Load(name)
+		if errors.Is(err, pokehelp.ErrNoProfile) {
+			fmt.Fprintln(config.Out, config.Msg("profile.unknown", name))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := config.SaveProfile(); err != nil {
+			return err
+		}
+		config.UseProfile(profile)
+		fmt.Fprintln(config.Out, config.Msg("profile.switched", name, len(profile.Pokedex)))
+	case "delete":
+		if name == config.Profile {
+			fmt.Fprintln(config.Out, config.Msg("profile.delete_current"))
+			return nil
+		}
+		if err := config.Storage.Delete(name); errors.Is(err, pokehelp.ErrNoProfile) {
+			fmt.Fprintln(config.Out, config.Msg("profile.unknown", name))
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fmt.Fprintln(config.Out, config.Msg("profile.deleted", name))
+	default:
+		fmt.Fprintln(config.Out, config.Msg("profile.usage"))
+	}
+
+	return nil
+}
+
+// CommandConfig shows the settings and where each one came from. `config
+// get KEY` shows one and `config set KEY VALUE` writes it to the config
+// file.
+func CommandConfig(config *pokehelp.RequestConfig, args ...[]string) error {
+	words := args[0]
+	if len(words) == 0 || words[0] == "" || words[0] == "show" && len(words) == 1 {
+		if config.Settings != nil && config.Settings.Path != "" {
+			fmt.Fprintln(config.Out, config.Msg("config.file", config.Settings.Path))
+		}
+		for _, key := range pokehelp.SettingKeys() {
+			value, source := config.Settings.Get(key)
+			fmt.Fprintf(config.Out, "%s = %q (%s)\n", key, value, source)
+		}
+		return nil
+	}
+
+	switch {
+	case words[0] == "get" && len(words) == 2:
+		if _, ok := pokehelp.LookupSetting(words[1]); !ok {
+			fmt.Fprintln(config.Out, config.Msg("config.unknown", words[1], strings.Join(pokehelp.SettingKeys(), ", ")))
+			return nil
+		}
+		fmt.Fprintln(config.Out, config.Settings.String(words[1]))
+	case words[0] == "set" && len(words) == 3:
+		key, value := words[1], words[2]
+		if config.Settings == nil {
+			fmt.Fprintln(config.Out, config.Msg("config.disabled"))
+			return nil
+		}
+		if err := pokehelp.ValidateSetting(key, value); err != nil {
+			fmt.Fprintln(config.Out, config.Msg("config.invalid", err))
+			return nil
+		}
+		if err := config.Settings.Set(key, value); err != nil {
+			return err
+		}
+		fmt.Fprintln(config.Out, config.Msg("config.set", key, value, config.Settings.Path))
+
+		setting, _ := pokehelp.LookupSetting(key)
+		if config.Settings.Overridden(key) {
+			fmt.Fprintln(config.Out, config.Msg("config.overridden", setting.Env, setting.Flag))
+			return nil
+		}
+		applySetting(config, key, value)
+	default:
+		fmt.Fprintln(config.Out, config.Msg("config.usage"))
+	}
+
+	return nil
+}
+
+// applySetting makes a setting that was just set take effect in the
+// session, the ones that aren't read as they're used
+func applySetting(config *pokehelp.RequestConfig, key string, value string) {
+	switch key {
+	case "cache_ttl":
+		fmt.Fprintln(config.Out, config.Msg("config.restart"))
+	case "api_url":
+		config.APIURL = value
+		if config.Store != nil {
+			config.Store.BaseURL = value
+		}
+		// The names of the old API aren't the ones to check against
+		config.Index = nil
+	case "page_size":
+		config.Pager.SetLimit(config.Settings.Int(key))
+	case "lang":
+		config.Lang = value
+	case "game":
+		config.Game = nil
+		if game, ok := pokehelp.LookupGameVersion(value); ok {
+			config.Game = &game
+		}
+	}
+}
+
+// CommandCache shows how many responses are cached, `cache clear` throws
+// them out along with the name index
+func CommandCache(config *pokehelp.RequestConfig, args ...[]string) error {
+	switch strings.Join(args[0], " ") {
+	case "":
+		fmt.Fprintln(config.Out, config.Msg("cache.size", config.Cache.Len()))
+	case "clear":
+		n := config.Cache.Clear()
+		if err := pokehelp.ClearNameIndex(config); err != nil {
+			return err
+		}
+		fmt.Fprintln(config.Out, config.Msg("cache.cleared", n))
+	default:
+		fmt.Fprintln(config.Out, config.Msg("cache.usage"))
 	}
 
-	return errors.New("something went wrong in pokedex")
+	return nil
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/peterh/liner"
+
+	"github.com/munanadi/pokedex/pokehelp"
+	"github.com/munanadi/pokedex/pokelang"
+)
+
+// completeLine returns the candidates for the last word of line, command
+// names for the first word and then whatever that command takes
+func completeLine(config *pokehelp.RequestConfig, line string) []string {
+	words := strings.Split(line, " ")
+	last := words[len(words)-1]
+
+	if len(words) == 1 {
+		names := []string{}
+		for name := range getCommands() {
+			names = append(names, name)
+		}
+		return pokehelp.CompleteFrom(names, last)
+	}
+
+	// inspect pikachu --sprite <TAB>
+	if words[0] == "inspect" && len(words) > 2 && words[len(words)-2] == "--sprite" {
+		return pokehelp.CompleteFrom(pokehelp.SpriteVariants, last)
+	}
+
+	// export --format <TAB>
+	if (words[0] == "export" || words[0] == "import") && words[len(words)-2] == "--format" {
+		return pokehelp.CompleteFrom(pokehelp.ExportFormats, last)
+	}
+
+	// team add <TAB>
+	if words[0] == "team" && len(words) == 3 && (words[1] == "add" || words[1] == "remove") {
+		return pokehelp.CompleteFrom(caughtNames(config), last)
+	}
+
+	// profile switch <TAB>
+	if words[0] == "profile" && len(words) == 3 && (words[1] == "switch" || words[1] == "delete") && config.Storage != nil {
+		names, _ := config.Storage.List()
+		return pokehelp.CompleteFrom(names, last)
+	}
+
+	// config set <TAB>
+	if words[0] == "config" && len(words) == 3 && (words[1] == "get" || words[1] == "set") {
+		return pokehelp.CompleteFrom(pokehelp.SettingKeys(), last)
+	}
+
+	if len(words) > 2 {
+		return nil
+	}
+
+	switch words[0] {
+	case "catch", "explore":
+		index, err := pokehelp.GetNameIndex(config)
+		if err != nil {
+			return nil
+		}
+		if words[0] == "catch" {
+			return index.Complete(pokehelp.KindPokemon, last)
+		}
+		return index.Complete(pokehelp.KindLocationArea, last)
+	case "inspect", "moves":
+		return pokehelp.CompleteFrom(caughtNames(config), last)
+	case "profile":
+		return pokehelp.CompleteFrom([]string{"list", "new", "switch", "delete"}, last)
+	case "cache":
+		return pokehelp.CompleteFrom([]string{"clear"}, last)
+	case "config":
+		return pokehelp.CompleteFrom([]string{"show", "get", "set"}, last)
+	case "team":
+		return pokehelp.CompleteFrom([]string{"add", "remove", "export", "import"}, last)
+	case "lang":
+		return pokehelp.CompleteFrom(pokelang.Supported(), last)
+	case "game":
+		return pokehelp.CompleteFrom(append(pokehelp.GameVersionNames(), "all"), last)
+	}
+
+	return nil
+}
+
+// completeWord is the liner word completer, it only ever completes
+// the word the cursor is at the end of
+func completeWord(config *pokehelp.RequestConfig) liner.WordCompleter {
+	return func(line string, pos int) (string, []string, string) {
+		head, tail := line[:pos], line[pos:]
+
+		start := strings.LastIndex(head, " ") + 1
+		candidates := completeLine(config, head)
+
+		completions := make([]string, 0, len(candidates))
+		for _, v := range candidates {
+			completions = append(completions, v+" ")
+		}
+
+		return head[:start], completions, tail
+	}
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/peterh/liner"
+
+	"github.com/munanadi/pokedex/pokeapitest"
 	"github.com/munanadi/pokedex/pokecache"
 	"github.com/munanadi/pokedex/pokehelp"
+	"github.com/munanadi/pokedex/pokelog"
+	"github.com/munanadi/pokedex/pokestore"
 )
 
 type cliCommand struct {
@@ -31,14 +42,29 @@ func getCommands() map[string]cliCommand {
 		},
 		"map": {
 			name:        "map",
-			description: "Lets you explore the map in skips of 20",
+			description: "Lets you explore the map a page at a time, takes --page N, --limit N, first and last",
 			callback:    CommandMap,
 		},
 		"mapb": {
 			name:        "mapb",
-			description: "To go back 20 skips in map locations",
+			description: "To go back a page in map locations",
 			callback:    CommandMapb,
 		},
+		"region": {
+			name:        "region",
+			description: "Lists the regions, or picks one with `region <name>`",
+			callback:    CommandRegion,
+		},
+		"locations": {
+			name:        "locations",
+			description: "Lists the locations in the picked region",
+			callback:    CommandLocations,
+		},
+		"areas": {
+			name:        "areas",
+			description: "Lists the areas of a location to explore",
+			callback:    CommandAreas,
+		},
 		"explore": {
 			name:        "explore",
 			description: "Let's you explore a city area",
@@ -51,38 +77,232 @@ func getCommands() map[string]cliCommand {
 		},
 		"inspect": {
 			name:        "inspect",
-			description: "Let's you check on the Pokemon",
+			description: "Let's you check on the Pokemon, --sprite [shiny|back|gen1|...] draws it",
 			callback:    CommandInspect,
 		},
+		"moves": {
+			name:        "moves",
+			description: "Lists the moves of a caught Pokemon",
+			callback:    CommandMoves,
+		},
 		"pokedex": {
 			name:        "pokedex",
 			description: "Let's check your pokedex",
 			callback:    CommandPokedex,
 		},
+		"inventory": {
+			name:        "inventory",
+			description: "Lists the items in your bag",
+			callback:    CommandInventory,
+		},
+		"export": {
+			name:        "export",
+			description: "Writes your pokedex to a file, --format json, csv or markdown",
+			callback:    CommandExport,
+		},
+		"import": {
+			name:        "import",
+			description: "Adds the pokemon in an exported pokedex to yours",
+			callback:    CommandImport,
+		},
+		"team": {
+			name:        "team",
+			description: "Shows your team, `team add|remove <name>`, `team export|import` a Showdown paste",
+			callback:    CommandTeam,
+		},
+		"profile": {
+			name:        "profile",
+			description: "Shows the profile in use, `profile list|new|switch|delete` manages them",
+			callback:    CommandProfile,
+		},
+		"config": {
+			name:        "config",
+			description: "Shows the settings and where they come from, `config get|set <key>` reads or writes one",
+			callback:    CommandConfig,
+		},
+		"cache": {
+			name:        "cache",
+			description: "Shows how many responses are cached, `cache clear` throws them out",
+			callback:    CommandCache,
+		},
+		"snapshot": {
+			name:        "snapshot",
+			description: "Shows the offline snapshot, `snapshot download` or `snapshot import <DIR>` fills it",
+			callback:    CommandSnapshot,
+		},
+		"lang": {
+			name:        "lang",
+			description: "Shows or sets the language for names and messages",
+			callback:    CommandLang,
+		},
+		"game": {
+			name:        "game",
+			description: "Shows or sets the game version to filter by",
+			callback:    CommandGame,
+		},
 	}
 }
 
 func main() {
-	// Store values in cache for seconds specified here
-	const CACHE_REFRESH_IN_SECONDS int64 = 30
-
-	timeInterval := time.Duration(CACHE_REFRESH_IN_SECONDS) * time.Second
-	cache := pokecache.NewCache(timeInterval)
+	// Flags for the settings, they win over the environment and the
+	// config file
+	for _, setting := range pokehelp.AllSettings {
+		flag.String(setting.Flag, setting.Default, setting.Usage+", $"+setting.Env+" sets it as well")
+	}
+	configPath := flag.String("config", pokehelp.DefaultConfigPath(), "config file, the config command shows what can be set in it")
+	verbose := flag.Bool("verbose", false, "log what is being fetched to stderr")
+	debug := flag.Bool("debug", false, "log everything, including cache hits, to stderr")
+	logFile := flag.String("log-file", "", "write logs to this file instead of stderr")
+	offline := flag.Bool("offline", false, "serve everything from the local snapshot, see `snapshot download`")
+	snapshotDir := flag.String("snapshot-dir", pokehelp.DefaultStoreDir(), "where the local snapshot of the API is kept")
+	backend := flag.String("backend", "rest", "fetch pokemon and areas over rest, or over graphql asking only for the fields that are used")
+	graphqlURL := flag.String("graphql-url", pokehelp.DefaultGraphQLURL, "GraphQL endpoint for --backend graphql")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout for a single request to the API")
+	defaultProfile := os.Getenv(pokehelp.ProfileEnv)
+	if defaultProfile == "" {
+		defaultProfile = pokehelp.DefaultProfile
+	}
+	profile := flag.String("profile", defaultProfile, "profile to play as, created if it doesn't exist yet, $"+pokehelp.ProfileEnv+" picks it as well")
+	storage := flag.String("storage", "json", "keep profiles in json files, or in an sqlite database with the API's responses")
+	dbPath := flag.String("db", pokestore.DefaultPath(), "sqlite database for --storage sqlite")
+	flag.Usage = func() {
+		printUsage(flag.CommandLine.Output(), "")
+		fmt.Fprintln(flag.CommandLine.Output(), "\nFlags, given before the command:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-	pokedex := make(map[string]pokehelp.Pokemon)
+	logs, err := pokelog.Setup(*verbose, *debug, *logFile)
+	if err != nil {
+		log.Fatalf("opening log file failed: %s\n", err)
+	}
 
-	config := &pokehelp.RequestConfig{Next: nil, Prev: nil, Cache: cache, Pokedex: pokedex}
+	switch flag.Arg(0) {
+	case "serve-mock":
+		serveMock(flag.Args()[1:])
+		return
+	case "help":
+		if !printHelp(os.Stdout, flag.Args()[1:]) {
+			os.Exit(2)
+		}
+		return
+	}
 
-	for {
-		fmt.Printf("Pokedex > ")
-		scanner := bufio.NewScanner(os.Stdin)
-		if ok := scanner.Scan(); ok {
-			for k, v := range getCommands() {
-				args := strings.Split(scanner.Text(), " ")
-				if k == args[0] {
-					v.callback(config, args[1:])
-				}
+	settings, err := pokehelp.LoadSettings(*configPath, os.Getenv)
+	if err != nil {
+		log.Fatalf("loading the config failed: %s\n", err)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		for _, setting := range pokehelp.AllSettings {
+			if f.Name == setting.Flag {
+				err = errors.Join(err, settings.SetFlag(setting.Key, f.Value.String()))
 			}
 		}
+	})
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+	if !pokehelp.ValidProfileName(*profile) {
+		log.Fatalf("invalid profile name %s, use letters, digits, - and _\n", *profile)
 	}
+
+	// Responses are served from the cache for this long
+	timeInterval := settings.Duration("cache_ttl")
+
+	apiURL := settings.String("api_url")
+	store := pokehelp.NewStore(*snapshotDir)
+	store.BaseURL = apiURL
+
+	client := pokehelp.NewClient()
+	client.Timeout = *timeout
+
+	config := &pokehelp.RequestConfig{Pager: pokehelp.NewPager(settings.Int("page_size")), APIURL: apiURL, Client: client, Store: store, Offline: *offline, Settings: settings, Out: os.Stdout, In: os.Stdin, Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+	// The in-memory cache is only needed without the database, which
+	// keeps its own
+	var cache *pokecache.Cache
+	var db *pokestore.DB
+	switch *storage {
+	case "json":
+		config.Storage = pokehelp.NewProfiles(pokehelp.DefaultProfilesDir())
+		cache = pokecache.NewCache(timeInterval)
+		config.Cache = cache
+	case "sqlite":
+		db, err = pokestore.Open(*dbPath)
+		if err != nil {
+			log.Fatalf("opening the database failed: %s\n", err)
+		}
+		config.Storage = db
+		config.Cache = db.Cache(timeInterval)
+	default:
+		log.Fatalf("unknown storage %s, use json or sqlite\n", *storage)
+	}
+
+	save, err := config.Storage.Load(*profile)
+	if errors.Is(err, pokehelp.ErrNoProfile) {
+		save = pokehelp.NewProfile(*profile)
+	} else if err != nil {
+		log.Fatalf("loading profile failed: %s\n", err)
+	}
+	config.UseProfile(save)
+
+	switch *backend {
+	case "rest":
+	case "graphql":
+		config.Backend = pokehelp.NewGraphQLBackend(client, *graphqlURL)
+	default:
+		log.Fatalf("unknown backend %s, use rest or graphql\n", *backend)
+	}
+
+	// Without a command, or with repl, it's the interactive pokedex
+	interactive := flag.NArg() == 0 || flag.Arg(0) == "repl"
+	var line *liner.State
+	if interactive {
+		line = newLineEditor(config)
+	}
+
+	// Everything that has to happen on the way out, whichever way that is.
+	// It runs on this goroutine once the command that was running returned,
+	// so nothing changes the pokedex while it's saved.
+	shutdown := func() {
+		config.SaveProfile()
+		if line != nil {
+			closeLineEditor(line)
+		}
+		if cache != nil {
+			cache.Stop()
+		}
+		if db != nil {
+			db.Close()
+		}
+		logs.Close()
+	}
+
+	// SIGTERM and SIGHUP cancel the command that is running and end the
+	// session, which is then saved like after `exit`
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	if !interactive {
+		code := runCLI(ctx, config, os.Stderr, flag.Args())
+		shutdown()
+		os.Exit(code)
+	}
+	runRepl(ctx, config, line)
+	shutdown()
+}
+
+// serveMock runs the mock API from pokeapitest until killed, for demos
+// and poking at the CLI without hitting pokeapi.co
+func serveMock(args []string) {
+	flags := flag.NewFlagSet("serve-mock", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	flags.Parse(args)
+
+	apiURL := "http://" + *addr + "/api/v2/"
+	fmt.Printf("Serving the mock PokeAPI at %s\n", apiURL)
+	fmt.Printf("Point the pokedex at it with --api-url %s\n", apiURL)
+	fmt.Printf("and add --backend graphql --graphql-url http://%s%s to try GraphQL\n", *addr, pokeapitest.GraphQLPath)
+
+	log.Fatal(http.ListenAndServe(*addr, pokeapitest.NewHandler(apiURL)))
 }
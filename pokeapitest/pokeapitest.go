@@ -0,0 +1,326 @@
+// Package pokeapitest serves a small recorded copy of PokeAPI over HTTP,
+// so commands can be tested and demoed without hitting pokeapi.co.
+package pokeapitest
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+
+	"github.com/munanadi/pokedex/pokehelp"
+)
+
+// The fixtures use the same layout as the snapshot store and the
+// PokeAPI/api-data repo, `api/v2/<kind>/<id>/index.json`. The rows the
+// GraphQL API has for the same resources are in `graphql/<kind>/<id>.json`.
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixtures is the recorded API data the server serves
+func Fixtures() fs.FS {
+	fsys, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
+
+// GraphQLPath is where the handler answers GraphQL queries, like
+// PokeAPI does at pokehelp.DefaultGraphQLURL
+const GraphQLPath = "/graphql/v1beta"
+
+// Handler serves the fixtures the way PokeAPI would, as if it was
+// hosted at apiURL. Lists are paginated with `offset` and `limit`,
+// resources can be fetched by id or by name and anything else is a 404.
+// The queries pokehelp.GraphQLBackend makes are answered at GraphQLPath.
+type Handler struct {
+	apiURL   string
+	store    *pokehelp.Store
+	requests atomic.Int64
+	sent     atomic.Int64
+}
+
+func NewHandler(apiURL string) *Handler {
+	apiURL = strings.TrimSuffix(apiURL, "/") + "/"
+
+	store := pokehelp.NewStoreFS(Fixtures())
+	store.BaseURL = apiURL
+
+	return &Handler{apiURL: apiURL, store: store}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.requests.Add(1)
+
+	if r.URL.Path == GraphQLPath && r.Method == http.MethodPost {
+		h.serveGraphQL(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Anything the fixtures don't have, or that isn't an API url at all
+	data, err := h.store.Get(r.URL.RequestURI())
+	if err != nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	// Links in the fixtures point at pokeapi.co, point them here instead
+	data = bytes.ReplaceAll(data, []byte(pokehelp.DefaultAPIURL), []byte(h.apiURL))
+
+	h.writeJSON(w, data)
+}
+
+// graphQLKinds maps the tables the GraphQL queries select from to the
+// kind of resource they are
+var graphQLKinds = map[string]string{
+	"pokemon_v2_pokemon":      "pokemon",
+	"pokemon_v2_locationarea": "location-area",
+}
+
+// serveGraphQL answers a query for a single resource filtered by id or
+// name with its row from the fixtures, cut down to the fields the query
+// selects like the API does
+func (h *Handler) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string `json:"query"`
+		Variables struct {
+			Where map[string]struct {
+				Eq any `json:"_eq"`
+			} `json:"where"`
+		} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parseSelection(req.Query)
+	if err != nil {
+		h.writeGraphQLError(w, err.Error())
+		return
+	}
+	var kind string
+	var fields selection
+	for table, selected := range query {
+		kind, fields = graphQLKinds[table], selected
+	}
+	key, ok := req.Variables.Where["id"]
+	if !ok {
+		key = req.Variables.Where["name"]
+	}
+	if len(query) != 1 || kind == "" || fields == nil || key.Eq == nil {
+		h.writeGraphQLError(w, "the mock only knows single pokemon and location areas")
+		return
+	}
+
+	// The REST fixtures know which id a name is
+	rows := []any{}
+	if data, err := h.store.Get(fmt.Sprintf("%s%s/%v", h.apiURL, kind, key.Eq)); err == nil {
+		var resource struct {
+			ID int `json:"id"`
+		}
+		json.Unmarshal(data, &resource)
+		if data, err := fs.ReadFile(Fixtures(), fmt.Sprintf("graphql/%s/%d.json", kind, resource.ID)); err == nil {
+			var row any
+			if err := json.Unmarshal(data, &row); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			selected, err := fields.filter(row)
+			if err != nil {
+				h.writeGraphQLError(w, err.Error())
+				return
+			}
+			rows = append(rows, selected)
+		}
+	}
+
+	data, err := json.Marshal(map[string]any{"data": map[string]any{"resource": rows}})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, data)
+}
+
+func (h *Handler) writeGraphQLError(w http.ResponseWriter, message string) {
+	data, _ := json.Marshal(map[string]any{"errors": []map[string]string{{"message": message}}})
+	h.writeJSON(w, data)
+}
+
+// selection is the fields a GraphQL query selects, each with the fields
+// it selects in turn or nil for a plain value
+type selection map[string]selection
+
+// parseSelection reads the fields a query selects. Arguments are skipped
+// and aliases are read as the field they alias, which is all the queries
+// pokehelp.GraphQLBackend makes need.
+func parseSelection(query string) (selection, error) {
+	_, body, ok := strings.Cut(query, "{")
+	if !ok {
+		return nil, errors.New("the query doesn't select anything")
+	}
+
+	root := selection{}
+	stack := []selection{root}
+	last := ""
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '(':
+			// Skip the arguments, which can nest parens of their own
+			depth := 1
+			for depth > 0 && i+1 < len(body) {
+				i++
+				switch body[i] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+			}
+		case c == ':':
+			// What came before was an alias, the field is what comes next
+			delete(stack[len(stack)-1], last)
+			last = ""
+		case c == '{':
+			if last == "" {
+				return nil, errors.New("the query selects fields of nothing")
+			}
+			fields := selection{}
+			stack[len(stack)-1][last] = fields
+			stack = append(stack, fields)
+			last = ""
+		case c == '}':
+			if len(stack) == 1 {
+				return root, nil
+			}
+			stack = stack[:len(stack)-1]
+			last = ""
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			j := i
+			for j+1 < len(body) && (body[j+1] == '_' || body[j+1] >= 'a' && body[j+1] <= 'z' || body[j+1] >= 'A' && body[j+1] <= 'Z' || body[j+1] >= '0' && body[j+1] <= '9') {
+				j++
+			}
+			last = body[i : j+1]
+			stack[len(stack)-1][last] = nil
+			i = j
+		}
+	}
+	return nil, errors.New("the query's braces don't match")
+}
+
+// filter keeps the fields of a row of the fixtures that are selected,
+// going into lists and the rows they hold
+func (s selection) filter(value any) (any, error) {
+	switch v := value.(type) {
+	case []any:
+		res := make([]any, len(v))
+		for i := range v {
+			var err error
+			if res[i], err = s.filter(v[i]); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case map[string]any:
+		res := map[string]any{}
+		for field, fields := range s {
+			value, ok := v[field]
+			if !ok {
+				return nil, fmt.Errorf("the fixtures don't have the field %s", field)
+			}
+			if fields == nil {
+				res[field] = value
+				continue
+			}
+			var err error
+			if res[field], err = fields.filter(value); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	return value, nil
+}
+
+// writeJSON sends data without the indentation of the fixtures, the
+// way the API does
+func (h *Handler) writeJSON(w http.ResponseWriter, data []byte) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err == nil {
+		data = compact.Bytes()
+	}
+
+	h.sent.Add(int64(len(data)))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(data)
+}
+
+// Requests is how many requests were served so far
+func (h *Handler) Requests() int64 {
+	return h.requests.Load()
+}
+
+// Sent is how many bytes of JSON were served so far
+func (h *Handler) Sent() int64 {
+	return h.sent.Load()
+}
+
+// Server is an in-process mock of PokeAPI for tests
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+// NewServer starts a mock PokeAPI, close it when done
+func NewServer() *Server {
+	server := httptest.NewUnstartedServer(nil)
+	handler := NewHandler("http://" + server.Listener.Addr().String() + "/api/v2/")
+	server.Config.Handler = handler
+	server.Start()
+
+	return &Server{Server: server, Handler: handler}
+}
+
+// APIURL is the base url to point the CLI at, like --api-url does
+func (s *Server) APIURL() string {
+	return s.Handler.apiURL
+}
+
+// GraphQLURL is the endpoint to point a pokehelp.GraphQLBackend at
+func (s *Server) GraphQLURL() string {
+	return s.URL + GraphQLPath
+}
+
+// Transport serves the fixtures in-process for requests to any host, as
+// if it was pokeapi.co itself. Handy as an http.Client's Transport.
+type Transport struct {
+	*Handler
+}
+
+func NewTransport() *Transport {
+	return &Transport{Handler: NewHandler(pokehelp.DefaultAPIURL)}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	res.Request = req
+	return res, nil
+}
@@ -0,0 +1,284 @@
+package pokeapitest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/munanadi/pokedex/pokehelp"
+)
+
+func get(t *testing.T, url string) (int, []byte) {
+	t.Helper()
+
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, body
+}
+
+func TestServerResources(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	for _, path := range []string{"pokemon/pikachu", "pokemon/25/", "pokemon-species/25/"} {
+		status, body := get(t, server.APIURL()+path)
+		if status != http.StatusOK || !strings.Contains(string(body), `"pikachu"`) {
+			t.Errorf("expected pikachu at %s but got %d", path, status)
+		}
+		if strings.Contains(string(body), "https://pokeapi.co") {
+			t.Errorf("expected links at %s to point at the mock server", path)
+		}
+	}
+
+	if status, _ := get(t, server.APIURL()+"pokemon/missingno"); status != http.StatusNotFound {
+		t.Errorf("expected a 404 but got %d", status)
+	}
+}
+
+func TestServerPagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	var page struct {
+		Count    int    `json:"count"`
+		Next     string `json:"next"`
+		Previous string `json:"previous"`
+		Results  []any  `json:"results"`
+	}
+
+	_, body := get(t, server.APIURL()+"location-area/?offset=0&limit=20")
+	json.Unmarshal(body, &page)
+	if len(page.Results) != 20 || page.Previous != "" || !strings.HasPrefix(page.Next, server.APIURL()) {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+
+	_, body = get(t, page.Next)
+	json.Unmarshal(body, &page)
+	if len(page.Results) != page.Count-20 || page.Next != "" || page.Previous == "" {
+		t.Errorf("unexpected last page %+v", page)
+	}
+}
+
+func mockClient(transport *Transport) *pokehelp.Client {
+	client := pokehelp.NewClient()
+	client.HTTP.Transport = transport
+	client.Limiter = nil
+	return client
+}
+
+func TestGraphQLBackendMatchesREST(t *testing.T) {
+	client := mockClient(NewTransport())
+	backend := pokehelp.NewGraphQLBackend(client, pokehelp.DefaultGraphQLURL)
+
+	for _, name := range []string{"pikachu", "25", "magikarp"} {
+		url := pokehelp.DefaultAPIURL + "pokemon/" + name
+		var rest, gql pokehelp.Pokemon
+		decode(t, client, url, &rest)
+		decode(t, backend, url, &gql)
+		if gql.Moves != nil || gql.Sprites != (pokehelp.PokemonSprites{}) {
+			t.Errorf("expected %s over GraphQL to come without moves and sprites", name)
+		}
+		decode(t, backend, url+"#"+pokehelp.PartMoves, &gql)
+		decode(t, backend, url+"#"+pokehelp.PartSprites, &gql)
+
+		if gql.ID != rest.ID || gql.Name != rest.Name || gql.Height != rest.Height || gql.Weight != rest.Weight ||
+			gql.Species != rest.Species || !reflect.DeepEqual(gql.Abilities, rest.Abilities) || !reflect.DeepEqual(gql.HeldItems, rest.HeldItems) ||
+			!reflect.DeepEqual(gql.Moves, rest.Moves) || !reflect.DeepEqual(gql.Stats, rest.Stats) ||
+			!reflect.DeepEqual(gql.Types, rest.Types) || !reflect.DeepEqual(gql.Sprites, rest.Sprites) {
+			t.Errorf("expected %s over GraphQL to match REST", name)
+		}
+	}
+
+	url := pokehelp.DefaultAPIURL + "location-area/canalave-city-area"
+	var rest, gql pokehelp.PokedexLocationExplore
+	decode(t, client, url, &rest)
+	decode(t, backend, url, &gql)
+	if gql.Name != rest.Name || gql.Location != rest.Location || !reflect.DeepEqual(gql.Names, rest.Names) ||
+		!reflect.DeepEqual(gql.PokemonEncounters, rest.PokemonEncounters) {
+		t.Errorf("expected the location area over GraphQL to match REST\nrest: %+v\ngql:  %+v", rest.PokemonEncounters, gql.PokemonEncounters)
+	}
+
+	if _, err := backend.Get(context.Background(), pokehelp.DefaultAPIURL+"pokemon/missingno"); !errors.Is(err, pokehelp.ErrNotFound) {
+		t.Errorf("expected not found but got %v", err)
+	}
+}
+
+func TestGraphQLSelectedFields(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	for _, test := range []struct {
+		query string
+		want  string
+	}{
+		{
+			query: `query { resource: pokemon_v2_pokemon(where: $where, limit: 1) { name pokemon_v2_pokemontypes(order_by: {slot: asc}) { pokemon_v2_type { name } } } }`,
+			want:  `{"data":{"resource":[{"name":"pikachu","pokemon_v2_pokemontypes":[{"pokemon_v2_type":{"name":"electric"}}]}]}}`,
+		},
+		{
+			query: `query { resource: pokemon_v2_pokemon(where: $where) { nickname } }`,
+			want:  `{"errors":[{"message":"the fixtures don't have the field nickname"}]}`,
+		},
+		{
+			query: `query { resource: pokemon_v2_move(where: $where) { name } }`,
+			want:  `{"errors":[{"message":"the mock only knows single pokemon and location areas"}]}`,
+		},
+	} {
+		body, _ := json.Marshal(map[string]any{
+			"query":     test.query,
+			"variables": map[string]any{"where": map[string]any{"name": map[string]any{"_eq": "pikachu"}}},
+		})
+		res, err := http.Post(server.GraphQLURL(), "application/json", strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", test.query, test.want, got)
+		}
+	}
+}
+
+func decode(t testing.TB, backend pokehelp.Backend, url string, v any) {
+	t.Helper()
+
+	data, err := backend.Get(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -bench Backend ./pokeapitest compares the bytes sent for what
+// `catch` fetches of a pokemon over REST and over GraphQL, and how long
+// decoding what ends up in the cache takes every time a command uses it.
+// The mock only sends the fields a GraphQL query selects, like the API.
+func BenchmarkBackendREST(b *testing.B) {
+	transport := NewTransport()
+	benchmarkBackend(b, transport, mockClient(transport))
+}
+
+func BenchmarkBackendGraphQL(b *testing.B) {
+	transport := NewTransport()
+	benchmarkBackend(b, transport, pokehelp.NewGraphQLBackend(mockClient(transport), pokehelp.DefaultGraphQLURL))
+}
+
+func benchmarkBackend(b *testing.B, transport *Transport, backend pokehelp.Backend) {
+	data, err := backend.Get(context.Background(), pokehelp.DefaultAPIURL+"pokemon/pikachu")
+	if err != nil {
+		b.Fatal(err)
+	}
+	sent := transport.Sent()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var pokemon pokehelp.PokemonSummary
+		if err := json.Unmarshal(data, &pokemon); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(sent), "sent-B")
+}
+
+// go test -bench Decode ./pokeapitest compares decoding all of a pokemon
+// with decoding the summary the Pokedex keeps
+func BenchmarkDecodePokemon(b *testing.B) {
+	benchmarkDecode(b, func() any { return &pokehelp.Pokemon{} })
+}
+
+func BenchmarkDecodePokemonSummary(b *testing.B) {
+	benchmarkDecode(b, func() any { return &pokehelp.PokemonSummary{} })
+}
+
+func benchmarkDecode(b *testing.B, newValue func() any) {
+	data := fullSizePokemon(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := json.Unmarshal(data, newValue()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// versionGroups are the version groups a move of the API's pikachu is
+// learnt in, most of them learn it in every one
+var versionGroups = []string{
+	"red-blue", "yellow", "gold-silver", "crystal", "ruby-sapphire",
+	"emerald", "firered-leafgreen", "diamond-pearl", "platinum",
+	"heartgold-soulsilver", "black-white", "black-2-white-2", "x-y",
+	"omega-ruby-alpha-sapphire", "sun-moon", "ultra-sun-ultra-moon",
+	"lets-go-pikachu-lets-go-eevee", "sword-shield", "scarlet-violet",
+}
+
+// fullSizePokemon is the pikachu fixture grown back to the size of the
+// API's body, about 250KB. The fixture only keeps three of its moves,
+// learnt in a few version groups, and moves are most of the real thing.
+func fullSizePokemon(b *testing.B) []byte {
+	b.Helper()
+
+	data, err := fs.ReadFile(Fixtures(), "api/v2/pokemon/25/index.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var pokemon map[string]any
+	if err := json.Unmarshal(data, &pokemon); err != nil {
+		b.Fatal(err)
+	}
+
+	moves := pokemon["moves"].([]any)
+	var grown []any
+	for i := 0; len(data) < 250<<10; i++ {
+		move := moves[i%len(moves)].(map[string]any)
+		detail := move["version_group_details"].([]any)[0].(map[string]any)
+
+		var details []any
+		for j, group := range versionGroups {
+			details = append(details, map[string]any{
+				"level_learned_at":  detail["level_learned_at"],
+				"move_learn_method": detail["move_learn_method"],
+				"version_group": map[string]any{
+					"name": group,
+					"url":  fmt.Sprintf("https://pokeapi.co/api/v2/version-group/%d/", j+1),
+				},
+			})
+		}
+		grown = append(grown, map[string]any{
+			"move": map[string]any{
+				"name": fmt.Sprintf("%s-%d", move["move"].(map[string]any)["name"], i),
+				"url":  fmt.Sprintf("https://pokeapi.co/api/v2/move/%d/", i+1),
+			},
+			"version_group_details": details,
+		})
+		pokemon["moves"] = grown
+
+		if data, err = json.Marshal(pokemon); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return data
+}
@@ -1,15 +1,20 @@
 package pokecache
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 )
 
+// staleFor is how long expired entries are kept around for GetStale
+const staleFor = 30 * time.Minute
+
 type Cache struct {
-	cache map[string]cacheEntry
-	mu    sync.Mutex
+	cache    map[string]cacheEntry
+	interval time.Duration
+	mu       sync.Mutex
+	stop     chan struct{}
+	once     sync.Once
 }
 
 type cacheEntry struct {
@@ -22,37 +27,64 @@ func (c *Cache) Add(key string, val []byte) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// fmt.Printf("---Storing key: %s\n", key)
-
 	c.cache[key] = cacheEntry{
 		createdAt: time.Now(),
 		val:       val,
 	}
 }
 
-// Get will set the cache value
+// Get will return the cached value if it is still fresh
 func (c *Cache) Get(key string) ([]byte, bool) {
-	// fmt.Printf("---Fetching value for key %s\n", key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.cache[key]
+	if !ok || time.Since(entry.createdAt) > c.interval {
+		return nil, false
+	}
+	return entry.val, true
+}
+
+// GetStale will return the cached value even if it has expired, as long
+// as it hasn't been reaped yet. Meant as a fallback when fetching fails.
+func (c *Cache) GetStale(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if _, ok := c.cache[key]; !ok {
+	entry, ok := c.cache[key]
+	if !ok {
 		return nil, false
 	}
-	return c.cache[key].val, true
+	return entry.val, true
+}
+
+// Len is the number of entries, expired ones included
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.cache)
+}
+
+// Clear throws out every entry and returns how many there were
+func (c *Cache) Clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.cache)
+	c.cache = map[string]cacheEntry{}
+	return n
 }
 
+// reapLoop throws out entries once they're too old to even be served stale
 func (c *Cache) reapLoop(timeInterval time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	for k, v := range c.cache {
-		timeSinceCreated, err := time.ParseDuration(time.Until(v.createdAt).String())
-		if err != nil {
-			log.Fatalln("error finding time difference")
-		}
-		timeSinceCreatedInSeonds := timeSinceCreated.Abs().Seconds()
-		if timeSinceCreatedInSeonds > timeInterval.Seconds() {
+		if time.Since(v.createdAt) > timeInterval+staleFor {
 			// older value, throw it out
-			// fmt.Printf("Deleting old value of key %s\n", k)
+			slog.Debug("reaping cache entry", "key", k)
 			delete(c.cache, k)
 		}
 	}
@@ -61,20 +93,32 @@ func (c *Cache) reapLoop(timeInterval time.Duration) {
 // NewCache will set a cache for a time duration
 func NewCache(timeInterval time.Duration) *Cache {
 	cache := &Cache{
-		cache: map[string]cacheEntry{},
-		mu:    sync.Mutex{},
+		cache:    map[string]cacheEntry{},
+		interval: timeInterval,
+		mu:       sync.Mutex{},
+		stop:     make(chan struct{}),
 	}
 
 	ticker := time.NewTicker(timeInterval)
-	// defer ticker.Stop()
 
 	go func() {
-		for range ticker.C {
-			// fmt.Printf("\n---Running cleanup function---\n")
-			cache.reapLoop(timeInterval)
-			fmt.Printf("Pokedex > ")
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cache.reapLoop(timeInterval)
+			case <-cache.stop:
+				return
+			}
 		}
 	}()
 
 	return cache
 }
+
+// Stop ends the reaper goroutine, it is safe to call more than once
+func (c *Cache) Stop() {
+	c.once.Do(func() {
+		close(c.stop)
+	})
+}
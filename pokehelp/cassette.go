@@ -0,0 +1,179 @@
+package pokehelp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CassetteEnv picks the cassette mode, "record", "replay" or "off"
+const CassetteEnv = "POKEDEX_CASSETTE"
+
+type CassetteMode string
+
+const (
+	// CassetteReplay serves only recorded responses and fails on anything else
+	CassetteReplay CassetteMode = "replay"
+	// CassetteRecord passes requests through and saves the responses
+	CassetteRecord CassetteMode = "record"
+	// CassetteOff passes requests through untouched
+	CassetteOff CassetteMode = "off"
+)
+
+// Interaction is a single recorded request and its response
+type Interaction struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// CassetteTransport is an http.RoundTripper that records responses to a
+// cassette file and replays them. Requests are matched on method, path
+// and query, so a cassette recorded against one host replays for any.
+type CassetteTransport struct {
+	Path string
+	Mode CassetteMode
+	// Next does the real requests when recording or off
+	Next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	dirty        bool
+}
+
+// NewCassetteTransport loads the cassette at path, a missing cassette is
+// fine when recording
+func NewCassetteTransport(path string, mode CassetteMode, next http.RoundTripper) (*CassetteTransport, error) {
+	t := &CassetteTransport{Path: path, Mode: mode, Next: next}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &t.interactions); err != nil {
+			return nil, fmt.Errorf("unmarshalling cassette %s: %w", path, err)
+		}
+	case mode == CassetteReplay:
+		return nil, fmt.Errorf("loading cassette %s: %w", path, err)
+	}
+
+	return t, nil
+}
+
+// CassetteModeFromEnv reads the mode from CassetteEnv, fallback if unset
+func CassetteModeFromEnv(fallback CassetteMode) (CassetteMode, error) {
+	switch mode := CassetteMode(os.Getenv(CassetteEnv)); mode {
+	case "":
+		return fallback, nil
+	case CassetteReplay, CassetteRecord, CassetteOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%s has to be one of replay, record or off, not %q", CassetteEnv, mode)
+	}
+}
+
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Mode == CassetteOff {
+		return t.Next.RoundTrip(req)
+	}
+
+	key := req.URL.RequestURI()
+
+	if t.Mode == CassetteReplay {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		for _, v := range t.interactions {
+			if v.Method == req.Method && v.URL == key {
+				return v.response(req), nil
+			}
+		}
+		return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, key, t.Path)
+	}
+
+	res, err := t.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Method:      req.Method,
+		URL:         key,
+		Status:      res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+		Body:        string(body),
+	}
+
+	t.mu.Lock()
+	t.record(interaction)
+	t.mu.Unlock()
+
+	return interaction.response(req), nil
+}
+
+// record replaces an earlier recording of the same request
+func (t *CassetteTransport) record(interaction Interaction) {
+	t.dirty = true
+	for i, v := range t.interactions {
+		if v.Method == interaction.Method && v.URL == interaction.URL {
+			t.interactions[i] = interaction
+			return
+		}
+	}
+	t.interactions = append(t.interactions, interaction)
+}
+
+// Save writes what was recorded to the cassette file, it does nothing
+// if nothing new was recorded
+func (t *CassetteTransport) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.Path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(t.Path, data, 0o644); err != nil {
+		return err
+	}
+
+	t.dirty = false
+	return nil
+}
+
+func (i Interaction) response(req *http.Request) *http.Response {
+	header := http.Header{}
+	if i.ContentType != "" {
+		header.Set("Content-Type", i.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+		StatusCode:    i.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(i.Body))),
+		ContentLength: int64(len(i.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,259 @@
+package pokehelp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without trying the API while it looks down
+var ErrCircuitOpen = errors.New("the API is unavailable, try again later")
+
+// StatusError is a response with a status code that isn't a success
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetching %s: response failed with status code %d", e.URL, e.Code)
+}
+
+// retryable is true for rate limiting and server side failures
+func (e *StatusError) retryable() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// Client fetches from the API with a timeout on each attempt, retries
+// with backoff and a circuit breaker that stops trying during an outage
+type Client struct {
+	HTTP *http.Client
+	// Timeout for a single attempt, retries get their own
+	Timeout time.Duration
+	// MaxRetries is how many times a failed request is tried again
+	MaxRetries int
+	// BaseDelay is doubled on every retry, up to MaxDelay. A Retry-After
+	// from the server is waited for up to MaxDelay as well.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Limiter keeps us within the API's fair use policy, nil for no limit
+	Limiter *RateLimiter
+
+	breaker *breaker
+	flights flightGroup
+}
+
+func NewClient() *Client {
+	return &Client{
+		HTTP:       &http.Client{},
+		Timeout:    10 * time.Second,
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+		Limiter:    NewRateLimiter(5, 10),
+		breaker:    newBreaker(5, 30*time.Second),
+	}
+}
+
+// Get fetches url, retrying on network errors, 429s and 5xxs
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, url, nil)
+}
+
+// Post sends a JSON body to url, retried like Get. Only meant for
+// requests that don't change anything, like GraphQL queries.
+func (c *Client) Post(ctx context.Context, url string, body []byte) ([]byte, error) {
+	return c.do(ctx, http.MethodPost, url, body)
+}
+
+func (c *Client) do(ctx context.Context, method, url string, reqBody []byte) ([]byte, error) {
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var body []byte
+		var retryAfter time.Duration
+		body, retryAfter, err = c.attempt(ctx, method, url, reqBody)
+
+		var statusErr *StatusError
+		switch {
+		case err == nil:
+			c.breaker.success()
+			return body, nil
+		case errors.Is(err, ErrNotFound):
+			// The API is fine, the thing just doesn't exist
+			c.breaker.success()
+			return nil, err
+		case ctx.Err() != nil:
+			c.breaker.cancelled()
+			return nil, ctx.Err()
+		case errors.As(err, &statusErr) && !statusErr.retryable():
+			c.breaker.success()
+			return nil, err
+		}
+
+		if attempt == c.MaxRetries {
+			break
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > 0 {
+			// A server asking for a day is retried sooner, a command
+			// doesn't wait that long
+			delay = min(retryAfter, c.MaxDelay)
+		}
+		slog.Info("retrying", "url", url, "attempt", attempt+1, "in", delay, "err", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			c.breaker.cancelled()
+			return nil, ctx.Err()
+		}
+	}
+
+	c.breaker.failure()
+	return nil, err
+}
+
+// attempt makes a single request, returning how long the server asked
+// to wait before trying again if it did
+func (c *Client) attempt(ctx context.Context, method, url string, reqBody []byte) ([]byte, time.Duration, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var bodyReader io.Reader
+	if reqBody != nil {
+		bodyReader = bytes.NewReader(reqBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		// Already says which url it was
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, 0, fmt.Errorf("fetching %s: %w", url, ErrNotFound)
+	}
+	if res.StatusCode > 299 {
+		return nil, parseRetryAfter(res.Header.Get("Retry-After")), &StatusError{URL: url, Code: res.StatusCode}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading %s: %w", url, err)
+	}
+
+	return body, 0, nil
+}
+
+// backoff is the exponential delay before retry number attempt,
+// with full jitter so clients don't retry in lockstep
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.BaseDelay << attempt
+	if delay <= 0 || delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or as a date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// breaker opens after threshold requests in a row failed, and lets a
+// single request through to test the waters once cooldown has passed
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Since(b.openedAt) < b.cooldown || b.probing {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// cancelled gives up a probe without counting it either way
+func (b *breaker) cancelled() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		if b.failures == b.threshold {
+			slog.Warn("the API keeps failing, pausing requests", "for", b.cooldown)
+		}
+		b.openedAt = time.Now()
+	}
+}
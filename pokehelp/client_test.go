@@ -0,0 +1,109 @@
+package pokehelp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient() *Client {
+	client := NewClient()
+	client.BaseDelay = time.Millisecond
+	client.MaxDelay = 5 * time.Millisecond
+	return client
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	body, err := testClient().Get(context.Background(), server.URL)
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("expected ok after retries but got %q, %v", body, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls but got %d", calls.Load())
+	}
+}
+
+func TestClientCapsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	body, err := testClient().Get(context.Background(), server.URL)
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("expected ok after a retry but got %q, %v", body, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the retry after at most MaxDelay but it took %s", elapsed)
+	}
+}
+
+func TestClientDoesNotRetryNotFound(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := testClient().Get(context.Background(), server.URL)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound but got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single call but got %d", calls.Load())
+	}
+}
+
+func TestClientBreakerOpens(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := testClient()
+	client.MaxRetries = 0
+	client.breaker = newBreaker(2, time.Hour)
+
+	client.Get(context.Background(), server.URL)
+	client.Get(context.Background(), server.URL)
+
+	if _, err := client.Get(context.Background(), server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen but got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected the open breaker to skip the request but got %d calls", calls.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("3"); got != 3*time.Second {
+		t.Errorf("expected 3s but got %s", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("expected 0 but got %s", got)
+	}
+}
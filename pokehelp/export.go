@@ -0,0 +1,440 @@
+package pokehelp
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Formats the Pokedex can be exported to and imported from
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// ExportFormats are the formats in the order they're listed in
+var ExportFormats = []string{FormatJSON, FormatCSV, FormatMarkdown}
+
+// FormatFromPath guesses the format from a file's extension, it is empty
+// for extensions it doesn't know
+func FormatFromPath(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	case ".md", ".markdown":
+		return FormatMarkdown
+	}
+	return ""
+}
+
+// StatNames are the stats of an entry, in the order of the csv and
+// markdown columns
+var StatNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// TypeNames are the 18 types a pokemon can have
+var TypeNames = []string{
+	"normal", "fighting", "flying", "poison", "ground", "rock", "bug", "ghost", "steel",
+	"fire", "water", "grass", "electric", "psychic", "ice", "dragon", "dark", "fairy",
+}
+
+// MaxNicknameLength is how long nicknames can be, 12 like in the games
+// since gen 6
+const MaxNicknameLength = 12
+
+// PokedexEntry is a caught pokemon as it's exported. In json a Pokedex
+// is an array of these objects:
+//
+//	species_id  national dex number of the species, like 25
+//	name        pokemon name as the API has it, like "pikachu"
+//	nickname    optional, at most 12 characters
+//	level       1 to 100
+//	types       one or two type names, like ["electric"]
+//	stats       base stats from 0 to 255 keyed by StatNames
+//	height      in decimetres
+//	weight      in hectograms
+//	caught_at   RFC 3339 time it was caught
+//	caught_in   optional location area it was caught in
+//	game        optional version picked with `game` at the time
+//
+// csv and markdown have a column for each of these, except stats which
+// have a column per stat and types which are joined with "|".
+type PokedexEntry struct {
+	SpeciesID int            `json:"species_id"`
+	Name      string         `json:"name"`
+	Nickname  string         `json:"nickname,omitempty"`
+	Level     int            `json:"level"`
+	Types     []string       `json:"types"`
+	Stats     map[string]int `json:"stats"`
+	Height    int            `json:"height"`
+	Weight    int            `json:"weight"`
+	CaughtAt  time.Time      `json:"caught_at"`
+	CaughtIn  string         `json:"caught_in,omitempty"`
+	Game      string         `json:"game,omitempty"`
+
+	// Row is where the entry was in the file it was imported from
+	Row int `json:"-"`
+}
+
+var slug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate checks the entry could be a caught pokemon
+func (e *PokedexEntry) Validate() error {
+	if !slug.MatchString(e.Name) {
+		return fmt.Errorf("name %q isn't a pokemon name", e.Name)
+	}
+	if e.SpeciesID <= 0 {
+		return fmt.Errorf("species_id has to be positive, not %d", e.SpeciesID)
+	}
+	if utf8.RuneCountInString(e.Nickname) > MaxNicknameLength {
+		return fmt.Errorf("nickname %q is longer than %d characters", e.Nickname, MaxNicknameLength)
+	}
+	if e.Level < 1 || e.Level > 100 {
+		return fmt.Errorf("level has to be 1 to 100, not %d", e.Level)
+	}
+	if len(e.Types) < 1 || len(e.Types) > 2 {
+		return fmt.Errorf("a pokemon has one or two types, not %d", len(e.Types))
+	}
+	for _, t := range e.Types {
+		if !slices.Contains(TypeNames, t) {
+			return fmt.Errorf("unknown type %q", t)
+		}
+	}
+	for name, value := range e.Stats {
+		if !slices.Contains(StatNames, name) {
+			return fmt.Errorf("unknown stat %q", name)
+		}
+		if value < 0 || value > 255 {
+			return fmt.Errorf("%s has to be 0 to 255, not %d", name, value)
+		}
+	}
+	if e.Height < 0 || e.Weight < 0 {
+		return errors.New("height and weight can't be negative")
+	}
+	if e.CaughtIn != "" && !slug.MatchString(e.CaughtIn) {
+		return fmt.Errorf("caught_in %q isn't a location area name", e.CaughtIn)
+	}
+	if e.Game != "" && !slug.MatchString(e.Game) {
+		return fmt.Errorf("game %q isn't a version name", e.Game)
+	}
+	return nil
+}
+
+// NewPokedexEntry is how a caught pokemon is exported
+func NewPokedexEntry(c CaughtPokemon) PokedexEntry {
+	entry := PokedexEntry{
+		SpeciesID: c.SpeciesID(),
+		Name:      c.Name,
+		Nickname:  c.Nickname,
+		Level:     c.Level,
+		Stats:     map[string]int{},
+		Height:    c.Height,
+		Weight:    c.Weight,
+		CaughtAt:  c.CaughtAt,
+		CaughtIn:  c.CaughtIn,
+		Game:      c.Game,
+	}
+	for _, t := range c.Types {
+		entry.Types = append(entry.Types, t.Type.Name)
+	}
+	for _, s := range c.Stats {
+		entry.Stats[s.Stat.Name] = s.BaseStat
+	}
+	return entry
+}
+
+// Caught is the entry as a caught pokemon, with what the entry doesn't
+// have taken from the pokemon's summary
+func (e *PokedexEntry) Caught(summary PokemonSummary) CaughtPokemon {
+	return CaughtPokemon{
+		PokemonSummary: summary,
+		Nickname:       e.Nickname,
+		Level:          e.Level,
+		CaughtAt:       e.CaughtAt,
+		CaughtIn:       e.CaughtIn,
+		Game:           e.Game,
+	}
+}
+
+// SpeciesID is the national dex number, the last part of the species url
+func (p *PokemonSummary) SpeciesID() int {
+	id, _ := strconv.Atoi(path.Base(p.Species.URL))
+	return id
+}
+
+// ImportError is a row of an import that was skipped, Row counts from 1
+// and doesn't count headers. For a Showdown paste it counts the sets.
+type ImportError struct {
+	Row int
+	Err error
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e ImportError) Unwrap() error {
+	return e.Err
+}
+
+// Export writes the entries in format
+func Export(w io.Writer, format string, entries []PokedexEntry) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if entries == nil {
+			entries = []PokedexEntry{}
+		}
+		return enc.Encode(entries)
+	case FormatCSV:
+		out := csv.NewWriter(w)
+		out.Write(columns())
+		for _, e := range entries {
+			out.Write(e.row())
+		}
+		out.Flush()
+		return out.Error()
+	case FormatMarkdown:
+		var b strings.Builder
+		writeMarkdownRow(&b, columns())
+		separators := make([]string, len(columns()))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		writeMarkdownRow(&b, separators)
+		for _, e := range entries {
+			writeMarkdownRow(&b, e.row())
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("unknown format %q, use one of %s", format, strings.Join(ExportFormats, ", "))
+}
+
+// Import reads entries in format. Rows that can't be read or don't
+// validate are left out and returned as ImportErrors, the error is only
+// set when nothing could be read at all.
+func Import(r io.Reader, format string) ([]PokedexEntry, []ImportError, error) {
+	var rows []parsedRow
+	switch format {
+	case FormatJSON:
+		var raw []json.RawMessage
+		if err := json.NewDecoder(r).Decode(&raw); err != nil {
+			return nil, nil, fmt.Errorf("reading json: %w", err)
+		}
+		for _, data := range raw {
+			var row parsedRow
+			row.err = json.Unmarshal(data, &row.entry)
+			rows = append(rows, row)
+		}
+	case FormatCSV:
+		in := csv.NewReader(r)
+		in.FieldsPerRecord = -1
+		records, err := in.ReadAll()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading csv: %w", err)
+		}
+		rows, err = recordRows(records)
+		if err != nil {
+			return nil, nil, err
+		}
+	case FormatMarkdown:
+		records, err := readMarkdownTable(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows, err = recordRows(records)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q, use one of %s", format, strings.Join(ExportFormats, ", "))
+	}
+
+	var entries []PokedexEntry
+	var bad []ImportError
+	for i, row := range rows {
+		err := row.err
+		if err == nil {
+			err = row.entry.Validate()
+		}
+		if err != nil {
+			bad = append(bad, ImportError{Row: i + 1, Err: err})
+			continue
+		}
+		row.entry.Row = i + 1
+		entries = append(entries, row.entry)
+	}
+	return entries, bad, nil
+}
+
+// columns are the csv and markdown headers
+func columns() []string {
+	cols := []string{"species_id", "name", "nickname", "level", "types"}
+	cols = append(cols, StatNames...)
+	return append(cols, "height", "weight", "caught_at", "caught_in", "game")
+}
+
+func (e *PokedexEntry) row() []string {
+	row := []string{strconv.Itoa(e.SpeciesID), e.Name, e.Nickname, strconv.Itoa(e.Level), strings.Join(e.Types, "|")}
+	for _, name := range StatNames {
+		row = append(row, strconv.Itoa(e.Stats[name]))
+	}
+	return append(row, strconv.Itoa(e.Height), strconv.Itoa(e.Weight), e.CaughtAt.Format(time.RFC3339), e.CaughtIn, e.Game)
+}
+
+// parsedRow is a row of an import before it is validated
+type parsedRow struct {
+	entry PokedexEntry
+	err   error
+}
+
+// recordRows maps csv or markdown records to entries by their header,
+// so columns can be in any order and the optional ones left out
+func recordRows(records [][]string) ([]parsedRow, error) {
+	if len(records) == 0 {
+		return nil, errors.New("no header row")
+	}
+	header := map[string]int{}
+	for i, name := range records[0] {
+		header[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"species_id", "name", "level", "types"} {
+		if _, ok := header[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+
+	var rows []parsedRow
+	for _, record := range records[1:] {
+		entry, err := parseRecord(header, record)
+		rows = append(rows, parsedRow{entry, err})
+	}
+	return rows, nil
+}
+
+func parseRecord(header map[string]int, record []string) (PokedexEntry, error) {
+	field := func(name string) string {
+		if i, ok := header[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	number := func(name string) (int, error) {
+		value := field(name)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("%s %q isn't a number", name, value)
+		}
+		return n, nil
+	}
+
+	e := PokedexEntry{
+		Name:     field("name"),
+		Nickname: field("nickname"),
+		Stats:    map[string]int{},
+		CaughtIn: field("caught_in"),
+		Game:     field("game"),
+	}
+	if types := field("types"); types != "" {
+		e.Types = strings.Split(types, "|")
+	}
+
+	var err error
+	for name, dst := range map[string]*int{"species_id": &e.SpeciesID, "level": &e.Level, "height": &e.Height, "weight": &e.Weight} {
+		if *dst, err = number(name); err != nil {
+			return e, err
+		}
+	}
+	for _, name := range StatNames {
+		if e.Stats[name], err = number(name); err != nil {
+			return e, err
+		}
+	}
+	if caughtAt := field("caught_at"); caughtAt != "" {
+		if e.CaughtAt, err = time.Parse(time.RFC3339, caughtAt); err != nil {
+			return e, fmt.Errorf("caught_at %q isn't an RFC 3339 time", caughtAt)
+		}
+	}
+	return e, nil
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		b.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+	}
+	b.WriteString("\n")
+}
+
+// readMarkdownTable reads the first table in r, skipping the separator
+// row under the header and any text around the table
+func readMarkdownTable(r io.Reader) ([][]string, error) {
+	var records [][]string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "|") {
+			if len(records) > 0 {
+				break
+			}
+			continue
+		}
+
+		cells := splitMarkdownRow(line)
+		if len(records) == 1 && isSeparatorRow(cells) {
+			continue
+		}
+		records = append(records, cells)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading markdown: %w", err)
+	}
+	return records, nil
+}
+
+// splitMarkdownRow splits "| a | b\|c |" into "a" and "b|c"
+func splitMarkdownRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func isSeparatorRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.Trim(cell, ":-") != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package pokehelp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	entries := []PokedexEntry{{
+		SpeciesID: 25,
+		Name:      "pikachu",
+		Nickname:  "Spar|ky, jr",
+		Level:     12,
+		Types:     []string{"electric"},
+		Stats:     map[string]int{"hp": 35, "attack": 55, "defense": 40, "special-attack": 50, "special-defense": 50, "speed": 90},
+		Height:    4,
+		Weight:    60,
+		CaughtAt:  time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		CaughtIn:  "viridian-forest-area",
+		Game:      "yellow",
+		Row:       1,
+	}, {
+		SpeciesID: 129,
+		Name:      "magikarp",
+		Level:     5,
+		Types:     []string{"water"},
+		Stats:     map[string]int{"hp": 20, "attack": 10, "defense": 55, "special-attack": 15, "special-defense": 20, "speed": 80},
+		Height:    9,
+		Weight:    100,
+		CaughtAt:  time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC),
+		Row:       2,
+	}}
+
+	for _, format := range ExportFormats {
+		var b bytes.Buffer
+		if err := Export(&b, format, entries); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+
+		got, bad, err := Import(&b, format)
+		if err != nil || len(bad) > 0 {
+			t.Fatalf("%s: expected a clean import but got %v %v", format, err, bad)
+		}
+		if !reflect.DeepEqual(got, entries) {
+			t.Errorf("%s: expected %+v but got %+v", format, entries, got)
+		}
+	}
+}
+
+func TestImportReportsBadRows(t *testing.T) {
+	csv := strings.Join([]string{
+		"name,species_id,level,types,hp",
+		"pikachu,25,12,electric,35",
+		"pikachu,25,0,electric,35",
+		"mew,151,50,psychic|shadow,100",
+		"Not A Name,1,5,grass,45",
+		"bulbasaur,one,5,grass|poison,45",
+		"bulbasaur,1,5,grass|poison,45",
+	}, "\n")
+
+	entries, bad, err := Import(strings.NewReader(csv), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	if want := []string{"pikachu", "bulbasaur"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v to be imported but got %v", want, names)
+	}
+
+	var rows []int
+	for _, e := range bad {
+		rows = append(rows, e.Row)
+	}
+	if want := []int{2, 3, 4, 5}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected rows %v to be reported but got %v: %v", want, rows, bad)
+	}
+
+	if _, _, err := Import(strings.NewReader("name,level\npikachu,5"), FormatCSV); err == nil {
+		t.Errorf("expected an error without the species_id and types columns")
+	}
+}
+
+func TestImportMarkdownSkipsText(t *testing.T) {
+	md := "# My team\n\n| name | species_id | level | types |\n|:--|--:|--|--|\n| eevee | 133 | 20 | normal |\n\nThat's all.\n"
+
+	entries, bad, err := Import(strings.NewReader(md), FormatMarkdown)
+	if err != nil || len(bad) > 0 {
+		t.Fatalf("expected a clean import but got %v %v", err, bad)
+	}
+	if len(entries) != 1 || entries[0].Name != "eevee" || entries[0].Level != 20 {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,71 @@
+package pokehelp
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup lets concurrent calls for the same key share the work of
+// whichever came first, like x/sync/singleflight
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	val  []byte
+	err  error
+	// waiters are the calls still waiting, the work is cancelled when the
+	// last of them gives up
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Do runs fn for key, unless it is already running in which case it
+// waits for that one and returns its result. fn gets a context of its own
+// so one caller giving up doesn't fail it for the others, every caller
+// stops waiting when its ctx is done.
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall{}
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			call.val, call.err = fn(callCtx)
+			cancel()
+
+			g.mu.Lock()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody wants the result anymore, the next call starts over
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,107 @@
+package pokehelp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/munanadi/pokedex/pokecache"
+)
+
+func TestConcurrentFetchesAreCoalesced(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"name":"pikachu"}`))
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Stop()
+	config := &RequestConfig{Cache: cache, Client: NewClient()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if data, err := GetCachedBodyFromUrl(server.URL, config); err != nil || len(data) == 0 {
+				t.Errorf("expected a body but got %q, %v", data, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected a single fetch but got %d", calls.Load())
+	}
+}
+
+func TestCancelledCallerLeavesSharedFetchRunning(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"name":"pikachu"}`))
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Stop()
+	client := NewClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := &RequestConfig{Cache: cache, Client: client, Ctx: ctx}
+	second := &RequestConfig{Cache: cache, Client: client}
+
+	firstErr := make(chan error)
+	go func() {
+		_, err := GetCachedBodyFromUrl(server.URL, first)
+		firstErr <- err
+	}()
+	// Let the first caller start the fetch before the second joins it
+	time.Sleep(20 * time.Millisecond)
+
+	secondErr := make(chan error)
+	go func() {
+		data, err := GetCachedBodyFromUrl(server.URL, second)
+		if err == nil && len(data) == 0 {
+			err = errors.New("empty body")
+		}
+		secondErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the first caller to stop with its ctx but got %v", err)
+	}
+	if err := <-secondErr; err != nil {
+		t.Errorf("expected the second caller to get the body but got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single fetch but got %d", calls.Load())
+	}
+}
+
+func TestRateLimiterWaits(t *testing.T) {
+	limiter := NewRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The burst covers two, the other two wait about 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected the limiter to hold requests back but took %s", elapsed)
+	}
+}
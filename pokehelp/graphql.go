@@ -0,0 +1,499 @@
+package pokehelp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultGraphQLURL is PokeAPI's GraphQL endpoint
+const DefaultGraphQLURL = "https://beta.pokeapi.co/graphql/v1beta"
+
+// GraphQLBackend asks PokeAPI's GraphQL endpoint for single pokemon and
+// location areas, selecting only the fields the commands use instead of
+// every sprite of every generation. The answers are reshaped into the
+// REST JSON, so the cache, the models and the commands can't tell the
+// difference. Anything else, like lists and species, goes over REST.
+//
+// A pokemon url is answered with the summary the CLI keeps of it, its
+// sprites and moves are only queried for urls ending in #sprites and
+// #moves, see PokemonSummary.Sprites and PokemonSummary.Moves.
+type GraphQLBackend struct {
+	// URL of the GraphQL endpoint, DefaultGraphQLURL if empty
+	URL string
+	// Client makes the requests, retries and all, for both APIs
+	Client *Client
+}
+
+// Parts of a pokemon that are queried on their own, as the fragment of
+// its url
+const (
+	PartSprites = "sprites"
+	PartMoves   = "moves"
+)
+
+// pokemonQueries are the queries for a pokemon url by its fragment
+var pokemonQueries = map[string]string{
+	"":          pokemonQuery,
+	PartSprites: pokemonSpritesQuery,
+	PartMoves:   pokemonMovesQuery,
+}
+
+func NewGraphQLBackend(client *Client, url string) *GraphQLBackend {
+	return &GraphQLBackend{URL: url, Client: client}
+}
+
+// Get answers a REST url, querying GraphQL for the kinds it knows
+func (g *GraphQLBackend) Get(ctx context.Context, rawUrl string) ([]byte, error) {
+	rawUrl, part, _ := strings.Cut(rawUrl, "#")
+	kind, id, query, err := splitApiUrl(rawUrl)
+	if err != nil || id == "" || len(query) > 0 {
+		return g.Client.Get(ctx, rawUrl)
+	}
+
+	// Links in the answer point at the REST API the url is from
+	base := rawUrl[:strings.Index(rawUrl, "/api/v2/")+len("/api/v2/")]
+
+	// Resources are looked up by id or by name, like REST does
+	where := map[string]any{"name": map[string]any{"_eq": id}}
+	if n, err := strconv.Atoi(id); err == nil {
+		where = map[string]any{"id": map[string]any{"_eq": n}}
+	}
+
+	var res any
+	switch kind {
+	case KindPokemon:
+		pokemonQuery, ok := pokemonQueries[part]
+		if !ok {
+			return nil, fmt.Errorf("querying %s: there's no part %q of a pokemon", rawUrl, part)
+		}
+		var rows []gqlPokemon
+		if err := g.query(ctx, rawUrl, pokemonQuery, where, &rows); err != nil {
+			return nil, err
+		}
+		switch part {
+		case PartSprites:
+			res = rows[0].restSprites()
+		case PartMoves:
+			res = rows[0].restMoves(base)
+		default:
+			res = rows[0].rest(base)
+		}
+	case KindLocationArea:
+		var rows []gqlLocationArea
+		if err := g.query(ctx, rawUrl, locationAreaQuery, where, &rows); err != nil {
+			return nil, err
+		}
+		res = rows[0].rest(base)
+	default:
+		return g.Client.Get(ctx, rawUrl)
+	}
+
+	return json.Marshal(res)
+}
+
+// query runs a query with a `$where` filter and decodes the rows it
+// selected as `resource`, an empty result is ErrNotFound
+func (g *GraphQLBackend) query(ctx context.Context, rawUrl, query string, where any, rows any) error {
+	endpoint := g.URL
+	if endpoint == "" {
+		endpoint = DefaultGraphQLURL
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": map[string]any{"where": where},
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := g.Client.Post(ctx, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Data struct {
+			Resource json.RawMessage `json:"resource"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := jsonUnmarshal(endpoint, data, &res); err != nil {
+		return err
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("querying %s: %s", rawUrl, res.Errors[0].Message)
+	}
+	if len(res.Data.Resource) == 0 || string(res.Data.Resource) == "[]" {
+		return fmt.Errorf("querying %s: %w", rawUrl, ErrNotFound)
+	}
+
+	return jsonUnmarshal(endpoint, res.Data.Resource, rows)
+}
+
+const pokemonQuery = `query pokemon($where: pokemon_v2_pokemon_bool_exp) {
+  resource: pokemon_v2_pokemon(where: $where, limit: 1) {
+    id
+    name
+    height
+    weight
+    base_experience
+    pokemon_species_id
+    pokemon_v2_pokemonspecy { name }
+    pokemon_v2_pokemonabilities { is_hidden slot pokemon_v2_ability { id name } }
+    pokemon_v2_pokemonitems(order_by: {id: asc}) { rarity pokemon_v2_item { id name } pokemon_v2_version { id name } }
+    pokemon_v2_pokemonstats { base_stat effort pokemon_v2_stat { id name } }
+    pokemon_v2_pokemontypes { slot pokemon_v2_type { id name } }
+  }
+}`
+
+const pokemonSpritesQuery = `query pokemonSprites($where: pokemon_v2_pokemon_bool_exp) {
+  resource: pokemon_v2_pokemon(where: $where, limit: 1) {
+    pokemon_v2_pokemonsprites { sprites }
+  }
+}`
+
+const pokemonMovesQuery = `query pokemonMoves($where: pokemon_v2_pokemon_bool_exp) {
+  resource: pokemon_v2_pokemon(where: $where, limit: 1) {
+    pokemon_v2_pokemonmoves(order_by: {id: asc}) {
+      level
+      pokemon_v2_move { id name }
+      pokemon_v2_movelearnmethod { id name }
+      pokemon_v2_versiongroup { id name }
+    }
+  }
+}`
+
+const locationAreaQuery = `query locationArea($where: pokemon_v2_locationarea_bool_exp) {
+  resource: pokemon_v2_locationarea(where: $where, limit: 1) {
+    id
+    name
+    game_index
+    pokemon_v2_location { id name }
+    pokemon_v2_locationareanames { name pokemon_v2_language { id name } }
+    pokemon_v2_encounters(order_by: {id: asc}) {
+      min_level
+      max_level
+      pokemon_v2_pokemon { id name }
+      pokemon_v2_version { id name }
+      pokemon_v2_encounterslot { rarity pokemon_v2_encountermethod { id name } }
+      pokemon_v2_encounterconditionvaluemaps { pokemon_v2_encounterconditionvalue { id name } }
+    }
+  }
+}`
+
+// gqlRef is how the GraphQL API nests a related resource
+type gqlRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// restRef is the same resource as REST links to it
+type restRef struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func (r gqlRef) rest(base, kind string) restRef {
+	return restRef{Name: r.Name, URL: fmt.Sprintf("%s%s/%d/", base, kind, r.ID)}
+}
+
+type gqlPokemon struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Height         int    `json:"height"`
+	Weight         int    `json:"weight"`
+	BaseExperience int    `json:"base_experience"`
+	SpeciesID      int    `json:"pokemon_species_id"`
+	Species        struct {
+		Name string `json:"name"`
+	} `json:"pokemon_v2_pokemonspecy"`
+	Abilities []struct {
+		IsHidden bool   `json:"is_hidden"`
+		Slot     int    `json:"slot"`
+		Ability  gqlRef `json:"pokemon_v2_ability"`
+	} `json:"pokemon_v2_pokemonabilities"`
+	HeldItems []struct {
+		Rarity  int    `json:"rarity"`
+		Item    gqlRef `json:"pokemon_v2_item"`
+		Version gqlRef `json:"pokemon_v2_version"`
+	} `json:"pokemon_v2_pokemonitems"`
+	Moves []struct {
+		Level        int    `json:"level"`
+		Move         gqlRef `json:"pokemon_v2_move"`
+		LearnMethod  gqlRef `json:"pokemon_v2_movelearnmethod"`
+		VersionGroup gqlRef `json:"pokemon_v2_versiongroup"`
+	} `json:"pokemon_v2_pokemonmoves"`
+	Sprites []struct {
+		Sprites json.RawMessage `json:"sprites"`
+	} `json:"pokemon_v2_pokemonsprites"`
+	Stats []struct {
+		BaseStat int    `json:"base_stat"`
+		Effort   int    `json:"effort"`
+		Stat     gqlRef `json:"pokemon_v2_stat"`
+	} `json:"pokemon_v2_pokemonstats"`
+	Types []struct {
+		Slot int    `json:"slot"`
+		Type gqlRef `json:"pokemon_v2_type"`
+	} `json:"pokemon_v2_pokemontypes"`
+}
+
+type restAbility struct {
+	Ability  restRef `json:"ability"`
+	IsHidden bool    `json:"is_hidden"`
+	Slot     int     `json:"slot"`
+}
+
+type restHeldItem struct {
+	Item           restRef                `json:"item"`
+	VersionDetails []restHeldItemVersions `json:"version_details"`
+}
+
+type restHeldItemVersions struct {
+	Rarity  int     `json:"rarity"`
+	Version restRef `json:"version"`
+}
+
+type restMove struct {
+	Move                restRef            `json:"move"`
+	VersionGroupDetails []restMoveVersions `json:"version_group_details"`
+}
+
+type restMoveVersions struct {
+	LevelLearnedAt  int     `json:"level_learned_at"`
+	MoveLearnMethod restRef `json:"move_learn_method"`
+	VersionGroup    restRef `json:"version_group"`
+}
+
+type restStat struct {
+	BaseStat int     `json:"base_stat"`
+	Effort   int     `json:"effort"`
+	Stat     restRef `json:"stat"`
+}
+
+type restType struct {
+	Slot int     `json:"slot"`
+	Type restRef `json:"type"`
+}
+
+// restPokemon holds the fields of a REST pokemon that are in its summary
+type restPokemon struct {
+	ID             int            `json:"id"`
+	Name           string         `json:"name"`
+	Height         int            `json:"height"`
+	Weight         int            `json:"weight"`
+	BaseExperience int            `json:"base_experience"`
+	Species        restRef        `json:"species"`
+	Abilities      []restAbility  `json:"abilities"`
+	HeldItems      []restHeldItem `json:"held_items"`
+	Stats          []restStat     `json:"stats"`
+	Types          []restType     `json:"types"`
+}
+
+// restPokemonSprites and restPokemonMoves are the parts of a REST pokemon
+// that are queried on their own
+type restPokemonSprites struct {
+	Sprites json.RawMessage `json:"sprites"`
+}
+
+type restPokemonMoves struct {
+	Moves []restMove `json:"moves"`
+}
+
+func (p gqlPokemon) rest(base string) restPokemon {
+	res := restPokemon{
+		ID:             p.ID,
+		Name:           p.Name,
+		Height:         p.Height,
+		Weight:         p.Weight,
+		BaseExperience: p.BaseExperience,
+		Species:        gqlRef{ID: p.SpeciesID, Name: p.Species.Name}.rest(base, "pokemon-species"),
+		Abilities:      []restAbility{},
+		HeldItems:      []restHeldItem{},
+		Stats:          []restStat{},
+		Types:          []restType{},
+	}
+
+	for _, v := range p.Abilities {
+		res.Abilities = append(res.Abilities, restAbility{Ability: v.Ability.rest(base, "ability"), IsHidden: v.IsHidden, Slot: v.Slot})
+	}
+
+	// GraphQL has a row per item and version, REST groups them by item
+	items := map[string]int{}
+	for _, v := range p.HeldItems {
+		i, ok := items[v.Item.Name]
+		if !ok {
+			i = len(res.HeldItems)
+			items[v.Item.Name] = i
+			res.HeldItems = append(res.HeldItems, restHeldItem{Item: v.Item.rest(base, "item")})
+		}
+		res.HeldItems[i].VersionDetails = append(res.HeldItems[i].VersionDetails, restHeldItemVersions{
+			Rarity:  v.Rarity,
+			Version: v.Version.rest(base, "version"),
+		})
+	}
+
+	for _, v := range p.Stats {
+		res.Stats = append(res.Stats, restStat{BaseStat: v.BaseStat, Effort: v.Effort, Stat: v.Stat.rest(base, "stat")})
+	}
+	for _, v := range p.Types {
+		res.Types = append(res.Types, restType{Slot: v.Slot, Type: v.Type.rest(base, "type")})
+	}
+
+	return res
+}
+
+func (p gqlPokemon) restSprites() restPokemonSprites {
+	res := restPokemonSprites{Sprites: json.RawMessage("{}")}
+	if len(p.Sprites) > 0 {
+		res.Sprites = unquoteJSON(p.Sprites[0].Sprites)
+	}
+	return res
+}
+
+func (p gqlPokemon) restMoves(base string) restPokemonMoves {
+	res := restPokemonMoves{Moves: []restMove{}}
+
+	// GraphQL has a row per move and version group, REST groups them by move
+	moves := map[string]int{}
+	for _, v := range p.Moves {
+		i, ok := moves[v.Move.Name]
+		if !ok {
+			i = len(res.Moves)
+			moves[v.Move.Name] = i
+			res.Moves = append(res.Moves, restMove{Move: v.Move.rest(base, "move")})
+		}
+		res.Moves[i].VersionGroupDetails = append(res.Moves[i].VersionGroupDetails, restMoveVersions{
+			LevelLearnedAt:  v.Level,
+			MoveLearnMethod: v.LearnMethod.rest(base, "move-learn-method"),
+			VersionGroup:    v.VersionGroup.rest(base, "version-group"),
+		})
+	}
+
+	return res
+}
+
+// unquoteJSON undoes the API handing out some JSON columns as strings
+func unquoteJSON(raw json.RawMessage) json.RawMessage {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return json.RawMessage(s)
+	}
+	return raw
+}
+
+type gqlLocationArea struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	GameIndex int    `json:"game_index"`
+	Location  gqlRef `json:"pokemon_v2_location"`
+	Names     []struct {
+		Name     string `json:"name"`
+		Language gqlRef `json:"pokemon_v2_language"`
+	} `json:"pokemon_v2_locationareanames"`
+	Encounters []struct {
+		MinLevel int    `json:"min_level"`
+		MaxLevel int    `json:"max_level"`
+		Pokemon  gqlRef `json:"pokemon_v2_pokemon"`
+		Version  gqlRef `json:"pokemon_v2_version"`
+		Slot     struct {
+			Rarity int    `json:"rarity"`
+			Method gqlRef `json:"pokemon_v2_encountermethod"`
+		} `json:"pokemon_v2_encounterslot"`
+		Conditions []struct {
+			Value gqlRef `json:"pokemon_v2_encounterconditionvalue"`
+		} `json:"pokemon_v2_encounterconditionvaluemaps"`
+	} `json:"pokemon_v2_encounters"`
+}
+
+type restName struct {
+	Language restRef `json:"language"`
+	Name     string  `json:"name"`
+}
+
+type restEncounter struct {
+	Pokemon        restRef              `json:"pokemon"`
+	VersionDetails []restEncounterRates `json:"version_details"`
+}
+
+type restEncounterRates struct {
+	EncounterDetails []restEncounterDetail `json:"encounter_details"`
+	MaxChance        int                   `json:"max_chance"`
+	Version          restRef               `json:"version"`
+}
+
+type restEncounterDetail struct {
+	Chance          int       `json:"chance"`
+	ConditionValues []restRef `json:"condition_values"`
+	MaxLevel        int       `json:"max_level"`
+	Method          restRef   `json:"method"`
+	MinLevel        int       `json:"min_level"`
+}
+
+// restLocationArea holds only the fields of a REST location area that
+// were queried
+type restLocationArea struct {
+	ID                int             `json:"id"`
+	Name              string          `json:"name"`
+	GameIndex         int             `json:"game_index"`
+	Location          restRef         `json:"location"`
+	Names             []restName      `json:"names"`
+	PokemonEncounters []restEncounter `json:"pokemon_encounters"`
+}
+
+func (a gqlLocationArea) rest(base string) restLocationArea {
+	res := restLocationArea{
+		ID:                a.ID,
+		Name:              a.Name,
+		GameIndex:         a.GameIndex,
+		Location:          a.Location.rest(base, "location"),
+		Names:             []restName{},
+		PokemonEncounters: []restEncounter{},
+	}
+
+	for _, v := range a.Names {
+		res.Names = append(res.Names, restName{Language: v.Language.rest(base, "language"), Name: v.Name})
+	}
+
+	// GraphQL has a row per encounter slot, REST groups them by pokemon
+	// and then by version
+	pokemon := map[string]int{}
+	for _, v := range a.Encounters {
+		i, ok := pokemon[v.Pokemon.Name]
+		if !ok {
+			i = len(res.PokemonEncounters)
+			pokemon[v.Pokemon.Name] = i
+			res.PokemonEncounters = append(res.PokemonEncounters, restEncounter{Pokemon: v.Pokemon.rest(base, "pokemon")})
+		}
+		encounter := &res.PokemonEncounters[i]
+
+		j := 0
+		for j < len(encounter.VersionDetails) && encounter.VersionDetails[j].Version.Name != v.Version.Name {
+			j++
+		}
+		if j == len(encounter.VersionDetails) {
+			encounter.VersionDetails = append(encounter.VersionDetails, restEncounterRates{Version: v.Version.rest(base, "version")})
+		}
+		rates := &encounter.VersionDetails[j]
+
+		conditions := []restRef{}
+		for _, c := range v.Conditions {
+			conditions = append(conditions, c.Value.rest(base, "encounter-condition-value"))
+		}
+		rates.EncounterDetails = append(rates.EncounterDetails, restEncounterDetail{
+			Chance:          v.Slot.Rarity,
+			ConditionValues: conditions,
+			MaxLevel:        v.MaxLevel,
+			Method:          v.Slot.Method.rest(base, "encounter-method"),
+			MinLevel:        v.MinLevel,
+		})
+		rates.MaxChance = min(rates.MaxChance+v.Slot.Rarity, 100)
+	}
+
+	return res
+}
@@ -1,28 +1,127 @@
 package pokehelp
 
 import (
-	"io"
-	"log"
-	"net/http"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 )
 
-func GetBodyFromUrl(url string, _config *RequestConfig) ([]byte, error) {
-	res, err := http.Get(url)
-	if err != nil {
-		log.Fatalln("something went wrong while fetching")
+// ErrNotFound is returned for urls the API doesn't know about
+var ErrNotFound = errors.New("not found")
+
+// GetBodyFromUrl fetches url with the configured client, or reads it
+// from the snapshot in offline mode
+func GetBodyFromUrl(url string, config *RequestConfig) ([]byte, error) {
+	return getBody(config.Context(), url, config)
+}
+
+func getBody(ctx context.Context, url string, config *RequestConfig) ([]byte, error) {
+	if config.Offline {
+		return config.Store.Get(url)
 	}
 
-	body, err := io.ReadAll(res.Body)
-	res.Body.Close()
+	return config.backend().Get(ctx, url)
+}
 
-	// TODO: Handle 404 and other stuff
-	if res.StatusCode > 299 {
-		log.Fatalf("Response failed with status code %d and body\n: %s\n", res.StatusCode, body)
+// GetCachedBodyFromUrl will return the body for url from the cache,
+// fetching and storing it if it isn't in there yet
+func GetCachedBodyFromUrl(url string, config *RequestConfig) ([]byte, error) {
+	if v, ok := config.Cache.Get(url); ok {
+		slog.Debug("found in cache", "url", url)
+		return v, nil
 	}
 
+	// Concurrent misses for the same url share a single fetch and Add, it
+	// carries on when one of them is cancelled and the others still wait
+	data, err := config.client().flights.Do(config.Context(), url, func(ctx context.Context) ([]byte, error) {
+		if v, ok := config.Cache.Get(url); ok {
+			// Another fetch finished while this one was getting started
+			return v, nil
+		}
+
+		slog.Info("not in cache, fetching", "url", url)
+		data, err := getBody(ctx, url, config)
+		if err == nil {
+			config.Cache.Add(url, data)
+		}
+		return data, err
+	})
 	if err != nil {
-		log.Fatalln("something went wrong while fetching")
+		// Better an old answer than none while the API is down
+		if !errors.Is(err, ErrNotFound) && config.Context().Err() == nil {
+			if v, ok := config.Cache.GetStale(url); ok {
+				slog.Warn("serving stale data", "url", url, "err", err)
+				return v, nil
+			}
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FetchInto gets url through the cache and decodes the JSON body into v
+func FetchInto(url string, config *RequestConfig, v any) error {
+	data, err := GetCachedBodyFromUrl(url, config)
+	if err != nil {
+		return err
+	}
+
+	return jsonUnmarshal(url, data, v)
+}
+
+func jsonUnmarshal(url string, data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshalling %s: %w", url, err)
 	}
+	return nil
+}
+
+// DefaultAPIURL is the base url of PokeAPI's REST endpoints
+const DefaultAPIURL = "https://pokeapi.co/api/v2/"
+
+// Endpoint is the url of the list endpoint for kind, like "pokemon",
+// with a trailing slash so a name or a query can be appended
+func (c *RequestConfig) Endpoint(kind string) string {
+	base := c.APIURL
+	if base == "" {
+		base = DefaultAPIURL
+	}
+	return strings.TrimSuffix(base, "/") + "/" + kind + "/"
+}
+
+// defaultClient is used when the config doesn't have a client of its own
+var defaultClient = NewClient()
 
-	return body, nil
+func (c *RequestConfig) client() *Client {
+	if c.Client == nil {
+		return defaultClient
+	}
+	return c.Client
+}
+
+// Backend fetches the JSON of a REST url of the API. Client does that
+// over REST, GraphQLBackend asks the GraphQL API for just what the CLI
+// uses and answers in the same shape.
+type Backend interface {
+	Get(ctx context.Context, url string) ([]byte, error)
+}
+
+func (c *RequestConfig) backend() Backend {
+	if c.Backend == nil {
+		return c.client()
+	}
+	return c.Backend
+}
+
+// Context is the context of the command being run, it is cancelled
+// when the user hits Ctrl-C
+func (c *RequestConfig) Context() context.Context {
+	if c.Ctx == nil {
+		return context.Background()
+	}
+	return c.Ctx
 }
@@ -0,0 +1,79 @@
+package pokehelp
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/munanadi/pokedex/pokelang"
+)
+
+// In picks the name for lang from the localized names, the API uses
+// codes like "ja-Hrkt" so a "ja" lookup will fall back to those too.
+// Returns fallback if there is no such name.
+func (n Names) In(lang, fallback string) string {
+	for _, v := range n {
+		if v.Language.Name == lang {
+			return v.Name
+		}
+	}
+
+	for _, v := range n {
+		if strings.HasPrefix(v.Language.Name, lang+"-") {
+			return v.Name
+		}
+	}
+
+	return fallback
+}
+
+// Localized is the name in the configured language, or the slug in the
+// default one like LocalizedNameFromUrl, so it can be typed back in
+func (n Names) Localized(config *RequestConfig, slug string) string {
+	if config.Lang == "" || config.Lang == pokelang.DefaultLang {
+		return slug
+	}
+	return n.In(config.Lang, slug)
+}
+
+// Msg formats a CLI message in the configured language
+func (c *RequestConfig) Msg(key string, args ...any) string {
+	return pokelang.Message(c.Lang, key, args...)
+}
+
+// LocalizedNameFromUrl fetches the resource at url and returns its name in
+// the configured language, the slug is returned for the default language
+// so nothing extra is fetched then.
+func LocalizedNameFromUrl(url string, slug string, config *RequestConfig) string {
+	if config.Lang == "" || config.Lang == pokelang.DefaultLang {
+		return slug
+	}
+
+	res, err := NamedAPIResource[LocalizedResource]{Name: slug, URL: url}.Resolve(config.Context(), config)
+	if err != nil {
+		return slug
+	}
+
+	return res.Names.In(config.Lang, slug)
+}
+
+// LocalizedNamesFromUrls is LocalizedNameFromUrl for a whole list, the
+// lookups run in parallel since each one can be a fetch
+func LocalizedNamesFromUrls(urls []string, slugs []string, config *RequestConfig) []string {
+	names := make([]string, len(slugs))
+	if config.Lang == "" || config.Lang == pokelang.DefaultLang {
+		copy(names, slugs)
+		return names
+	}
+
+	var wg sync.WaitGroup
+	for i := range slugs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			names[i] = LocalizedNameFromUrl(urls[i], slugs[i], config)
+		}(i)
+	}
+	wg.Wait()
+
+	return names
+}
@@ -1,329 +1,506 @@
 package pokehelp
 
-import "github.com/munanadi/pokedex/pokecache"
+import (
+	"context"
+	"io"
+	"math/rand"
+)
 
 type RequestConfig struct {
-	// *string cause it can be nill too
-	Next    *string
-	Prev    *string
-	Cache   *pokecache.Cache
-	Pokedex map[string]Pokemon
+	// Ctx is cancelled to abort the running command, see Context
+	Ctx context.Context
+	// Out is where commands write to, In is what `import -` reads
+	Out io.Writer
+	In  io.Reader
+	// Rand decides catches, seeded in tests so sessions are reproducible
+	Rand *rand.Rand
+	// Pager is the page of location areas `map` and `mapb` are on
+	Pager *Pager
+	Cache ResponseCache
+	// APIURL is the base url of the API, DefaultAPIURL if empty
+	APIURL string
+	// Client fetches from the API, a default one is made if nil
+	Client *Client
+	// Backend answers requests instead of Client when set, like a
+	// GraphQLBackend
+	Backend Backend
+	// Store is the local snapshot of the API, used instead of Client
+	// when Offline is set
+	Store   *Store
+	Offline bool
+	Pokedex map[string]CaughtPokemon
+	// Team are the names of the caught pokemon in the team, TeamSize at most
+	Team []string
+	// Seen are the pokemon met while exploring, by name
+	Seen map[string]SeenRecord
+	// Inventory is how many of each item the trainer has, by item name
+	Inventory map[string]int
+	// Profile is the name of the profile the session plays as, saved
+	// to Storage. Without Storage nothing is saved.
+	Profile string
+	Storage Storage
+	// Settings are the config file, environment and flags, nil has the
+	// defaults
+	Settings *Settings
+	// Lang is the language code used for names and messages, like "en" or "ja"
+	Lang string
+	// Game filters encounters, moves and sprites to a single version,
+	// nil shows everything
+	Game *GameVersion
+	// Region is the region picked with `region <name>`, empty if none
+	Region string
+	// Area is the location area explored last, where catches happen
+	Area string
+	// Index of all resource names, nil until first needed
+	Index *NameIndex
+}
+
+// LocalizedName is a name of a resource in a single language
+type LocalizedName struct {
+	Language NamedAPIResource[LocalizedResource] `json:"language"`
+	Name     string                              `json:"name"`
+}
+
+// Names is the `names` array the API returns on most resources
+type Names []LocalizedName
+
+// LocalizedResource decodes just the localized names of any resource,
+// like a pokemon-species, move, type or location-area
+type LocalizedResource struct {
+	Name  string `json:"name"`
+	Names Names  `json:"names"`
 }
 
 type PokedexLocations struct {
-	Count    int    `json:"count"`
-	Next     string `json:"next"`
-	Previous string `json:"previous"`
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
+	Count    int                                        `json:"count"`
+	Next     string                                     `json:"next"`
+	Previous string                                     `json:"previous"`
+	Results  []NamedAPIResource[PokedexLocationExplore] `json:"results"`
+}
+
+// ResourceList is a page of any of the API's list endpoints
+type ResourceList struct {
+	Count    int                                   `json:"count"`
+	Next     string                                `json:"next"`
+	Previous string                                `json:"previous"`
+	Results  []NamedAPIResource[LocalizedResource] `json:"results"`
+}
+
+type Region struct {
+	ID             int                                 `json:"id"`
+	Name           string                              `json:"name"`
+	Names          Names                               `json:"names"`
+	Locations      []NamedAPIResource[Location]        `json:"locations"`
+	MainGeneration NamedAPIResource[LocalizedResource] `json:"main_generation"`
+}
+
+type Location struct {
+	ID     int                                        `json:"id"`
+	Name   string                                     `json:"name"`
+	Names  Names                                      `json:"names"`
+	Region NamedAPIResource[Region]                   `json:"region"`
+	Areas  []NamedAPIResource[PokedexLocationExplore] `json:"areas"`
 }
 
 type PokedexLocationExplore struct {
 	EncounterMethodRates []struct {
-		EncounterMethod struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"encounter_method"`
-		VersionDetails []struct {
-			Rate    int `json:"rate"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
+		EncounterMethod NamedAPIResource[LocalizedResource] `json:"encounter_method"`
+		VersionDetails  []struct {
+			Rate    int                                 `json:"rate"`
+			Version NamedAPIResource[LocalizedResource] `json:"version"`
 		} `json:"version_details"`
 	} `json:"encounter_method_rates"`
-	GameIndex int `json:"game_index"`
-	ID        int `json:"id"`
-	Location  struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"location"`
-	Name  string `json:"name"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"language"`
-		Name string `json:"name"`
-	} `json:"names"`
-	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"pokemon"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int   `json:"chance"`
-				ConditionValues []any `json:"condition_values"`
-				MaxLevel        int   `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"pokemon_encounters"`
+	GameIndex         int                        `json:"game_index"`
+	ID                int                        `json:"id"`
+	Location          NamedAPIResource[Location] `json:"location"`
+	Name              string                     `json:"name"`
+	Names             Names                      `json:"names"`
+	PokemonEncounters []PokemonEncounter         `json:"pokemon_encounters"`
+}
+
+// PokemonEncounter is a pokemon that can be found in a location area,
+// with the details for every version it shows up in
+type PokemonEncounter struct {
+	Pokemon        NamedAPIResource[Pokemon] `json:"pokemon"`
+	VersionDetails []struct {
+		EncounterDetails []struct {
+			Chance          int                                         `json:"chance"`
+			ConditionValues []NamedAPIResource[EncounterConditionValue] `json:"condition_values"`
+			MaxLevel        int                                         `json:"max_level"`
+			Method          NamedAPIResource[LocalizedResource]         `json:"method"`
+			MinLevel        int                                         `json:"min_level"`
+		} `json:"encounter_details"`
+		MaxChance int                                 `json:"max_chance"`
+		Version   NamedAPIResource[LocalizedResource] `json:"version"`
+	} `json:"version_details"`
 }
 
+// Pokemon is everything the API has on a pokemon, see PokemonSummary for
+// what the CLI keeps of it
 type Pokemon struct {
+	Abilities      []PokemonAbility                      `json:"abilities"`
+	BaseExperience int                                   `json:"base_experience"`
+	Forms          []NamedAPIResource[LocalizedResource] `json:"forms"`
+	GameIndices    []struct {
+		GameIndex int                                 `json:"game_index"`
+		Version   NamedAPIResource[LocalizedResource] `json:"version"`
+	} `json:"game_indices"`
+	Height                 int                              `json:"height"`
+	HeldItems              []PokemonHeldItem                `json:"held_items"`
+	ID                     int                              `json:"id"`
+	IsDefault              bool                             `json:"is_default"`
+	LocationAreaEncounters string                           `json:"location_area_encounters"`
+	Moves                  []PokemonMove                    `json:"moves"`
+	Name                   string                           `json:"name"`
+	Order                  int                              `json:"order"`
+	PastAbilities          []PokemonPastAbilities           `json:"past_abilities"`
+	PastTypes              []PokemonPastTypes               `json:"past_types"`
+	Species                NamedAPIResource[PokemonSpecies] `json:"species"`
+	Sprites                PokemonSprites                   `json:"sprites"`
+	Stats                  []PokemonStat                    `json:"stats"`
+	Types                  []PokemonType                    `json:"types"`
+	Weight                 int                              `json:"weight"`
+}
+
+// PokemonMove is a move a pokemon can learn, with how and at what level
+// in each version group
+type PokemonMove struct {
+	Move                NamedAPIResource[Move] `json:"move"`
+	VersionGroupDetails []struct {
+		LevelLearnedAt  int                                 `json:"level_learned_at"`
+		MoveLearnMethod NamedAPIResource[LocalizedResource] `json:"move_learn_method"`
+		VersionGroup    NamedAPIResource[LocalizedResource] `json:"version_group"`
+	} `json:"version_group_details"`
+}
+
+type PokemonAbility struct {
+	Ability  NamedAPIResource[Ability] `json:"ability"`
+	IsHidden bool                      `json:"is_hidden"`
+	Slot     int                       `json:"slot"`
+}
+
+// PokemonHeldItem is an item a wild pokemon can be holding, with how
+// likely that is in each version
+type PokemonHeldItem struct {
+	Item           NamedAPIResource[Item] `json:"item"`
+	VersionDetails []struct {
+		Rarity  int                                 `json:"rarity"`
+		Version NamedAPIResource[LocalizedResource] `json:"version"`
+	} `json:"version_details"`
+}
+
+// Rarity is the chance in percent that the pokemon holds the item in
+// version, or in the version it's most likely in when version is empty
+func (h PokemonHeldItem) Rarity(version string) int {
+	rarity := 0
+	for _, v := range h.VersionDetails {
+		if version == "" || v.Version.Name == version {
+			rarity = max(rarity, v.Rarity)
+		}
+	}
+	return rarity
+}
+
+// PokemonPastAbilities are the abilities a pokemon had up to generation,
+// an ability is nil in slots that it didn't have back then
+type PokemonPastAbilities struct {
 	Abilities []struct {
-		Ability struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"ability"`
-		IsHidden bool `json:"is_hidden"`
-		Slot     int  `json:"slot"`
+		Ability  *NamedAPIResource[Ability] `json:"ability"`
+		IsHidden bool                       `json:"is_hidden"`
+		Slot     int                        `json:"slot"`
 	} `json:"abilities"`
-	BaseExperience int `json:"base_experience"`
-	Forms          []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"forms"`
-	GameIndices []struct {
-		GameIndex int `json:"game_index"`
-		Version   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"version"`
-	} `json:"game_indices"`
-	Height                 int    `json:"height"`
-	HeldItems              []any  `json:"held_items"`
-	ID                     int    `json:"id"`
-	IsDefault              bool   `json:"is_default"`
-	LocationAreaEncounters string `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-			VersionGroup struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Name          string `json:"name"`
-	Order         int    `json:"order"`
-	PastAbilities []any  `json:"past_abilities"`
-	PastTypes     []any  `json:"past_types"`
-	Species       struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"species"`
-	Sprites struct {
-		BackDefault      string `json:"back_default"`
-		BackFemale       any    `json:"back_female"`
-		BackShiny        string `json:"back_shiny"`
-		BackShinyFemale  any    `json:"back_shiny_female"`
-		FrontDefault     string `json:"front_default"`
-		FrontFemale      any    `json:"front_female"`
-		FrontShiny       string `json:"front_shiny"`
-		FrontShinyFemale any    `json:"front_shiny_female"`
-		Other            struct {
-			DreamWorld struct {
-				FrontDefault string `json:"front_default"`
-				FrontFemale  any    `json:"front_female"`
-			} `json:"dream_world"`
-			Home struct {
+	Generation NamedAPIResource[LocalizedResource] `json:"generation"`
+}
+
+// PokemonPastTypes are the types a pokemon had up to generation, like
+// the fairy types that were normal before generation vi
+type PokemonPastTypes struct {
+	Generation NamedAPIResource[LocalizedResource] `json:"generation"`
+	Types      []PokemonType                       `json:"types"`
+}
+
+type PokemonStat struct {
+	BaseStat int                                 `json:"base_stat"`
+	Effort   int                                 `json:"effort"`
+	Stat     NamedAPIResource[LocalizedResource] `json:"stat"`
+}
+
+type PokemonType struct {
+	Slot int                    `json:"slot"`
+	Type NamedAPIResource[Type] `json:"type"`
+}
+
+// PokemonSprites are the urls of every sprite of a pokemon, from every
+// generation of games
+type PokemonSprites struct {
+	BackDefault      string  `json:"back_default"`
+	BackFemale       *string `json:"back_female"`
+	BackShiny        string  `json:"back_shiny"`
+	BackShinyFemale  *string `json:"back_shiny_female"`
+	FrontDefault     string  `json:"front_default"`
+	FrontFemale      *string `json:"front_female"`
+	FrontShiny       string  `json:"front_shiny"`
+	FrontShinyFemale *string `json:"front_shiny_female"`
+	Other            struct {
+		DreamWorld struct {
+			FrontDefault string  `json:"front_default"`
+			FrontFemale  *string `json:"front_female"`
+		} `json:"dream_world"`
+		Home struct {
+			FrontDefault     string  `json:"front_default"`
+			FrontFemale      *string `json:"front_female"`
+			FrontShiny       string  `json:"front_shiny"`
+			FrontShinyFemale *string `json:"front_shiny_female"`
+		} `json:"home"`
+		OfficialArtwork struct {
+			FrontDefault string `json:"front_default"`
+			FrontShiny   string `json:"front_shiny"`
+		} `json:"official-artwork"`
+		Showdown struct {
+			BackDefault      string  `json:"back_default"`
+			BackFemale       *string `json:"back_female"`
+			BackShiny        string  `json:"back_shiny"`
+			BackShinyFemale  *string `json:"back_shiny_female"`
+			FrontDefault     string  `json:"front_default"`
+			FrontFemale      *string `json:"front_female"`
+			FrontShiny       string  `json:"front_shiny"`
+			FrontShinyFemale *string `json:"front_shiny_female"`
+		} `json:"showdown"`
+	} `json:"other"`
+	Versions struct {
+		GenerationI struct {
+			RedBlue struct {
+				BackDefault      string `json:"back_default"`
+				BackGray         string `json:"back_gray"`
+				BackTransparent  string `json:"back_transparent"`
+				FrontDefault     string `json:"front_default"`
+				FrontGray        string `json:"front_gray"`
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"red-blue"`
+			Yellow struct {
+				BackDefault      string `json:"back_default"`
+				BackGray         string `json:"back_gray"`
+				BackTransparent  string `json:"back_transparent"`
+				FrontDefault     string `json:"front_default"`
+				FrontGray        string `json:"front_gray"`
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"yellow"`
+		} `json:"generation-i"`
+		GenerationIi struct {
+			Crystal struct {
+				BackDefault           string `json:"back_default"`
+				BackShiny             string `json:"back_shiny"`
+				BackShinyTransparent  string `json:"back_shiny_transparent"`
+				BackTransparent       string `json:"back_transparent"`
+				FrontDefault          string `json:"front_default"`
+				FrontShiny            string `json:"front_shiny"`
+				FrontShinyTransparent string `json:"front_shiny_transparent"`
+				FrontTransparent      string `json:"front_transparent"`
+			} `json:"crystal"`
+			Gold struct {
+				BackDefault      string `json:"back_default"`
+				BackShiny        string `json:"back_shiny"`
 				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
 				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"home"`
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-				FrontShiny   string `json:"front_shiny"`
-			} `json:"official-artwork"`
-			Showdown struct {
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"gold"`
+			Silver struct {
 				BackDefault      string `json:"back_default"`
-				BackFemale       any    `json:"back_female"`
 				BackShiny        string `json:"back_shiny"`
-				BackShinyFemale  any    `json:"back_shiny_female"`
 				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
 				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"showdown"`
-		} `json:"other"`
-		Versions struct {
-			GenerationI struct {
-				RedBlue struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"red-blue"`
-				Yellow struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"yellow"`
-			} `json:"generation-i"`
-			GenerationIi struct {
-				Crystal struct {
-					BackDefault           string `json:"back_default"`
-					BackShiny             string `json:"back_shiny"`
-					BackShinyTransparent  string `json:"back_shiny_transparent"`
-					BackTransparent       string `json:"back_transparent"`
-					FrontDefault          string `json:"front_default"`
-					FrontShiny            string `json:"front_shiny"`
-					FrontShinyTransparent string `json:"front_shiny_transparent"`
-					FrontTransparent      string `json:"front_transparent"`
-				} `json:"crystal"`
-				Gold struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"gold"`
-				Silver struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"silver"`
-			} `json:"generation-ii"`
-			GenerationIii struct {
-				Emerald struct {
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"emerald"`
-				FireredLeafgreen struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"firered-leafgreen"`
-				RubySapphire struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"ruby-sapphire"`
-			} `json:"generation-iii"`
-			GenerationIv struct {
-				DiamondPearl struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"diamond-pearl"`
-				HeartgoldSoulsilver struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"heartgold-soulsilver"`
-				Platinum struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"platinum"`
-			} `json:"generation-iv"`
-			GenerationV struct {
-				BlackWhite struct {
-					Animated struct {
-						BackDefault      string `json:"back_default"`
-						BackFemale       any    `json:"back_female"`
-						BackShiny        string `json:"back_shiny"`
-						BackShinyFemale  any    `json:"back_shiny_female"`
-						FrontDefault     string `json:"front_default"`
-						FrontFemale      any    `json:"front_female"`
-						FrontShiny       string `json:"front_shiny"`
-						FrontShinyFemale any    `json:"front_shiny_female"`
-					} `json:"animated"`
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"black-white"`
-			} `json:"generation-v"`
-			GenerationVi struct {
-				OmegarubyAlphasapphire struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"omegaruby-alphasapphire"`
-				XY struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"x-y"`
-			} `json:"generation-vi"`
-			GenerationVii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-				UltraSunUltraMoon struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"ultra-sun-ultra-moon"`
-			} `json:"generation-vii"`
-			GenerationViii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-			} `json:"generation-viii"`
-		} `json:"versions"`
-	} `json:"sprites"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Effort   int `json:"effort"`
-		Stat     struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"type"`
-	} `json:"types"`
-	Weight int `json:"weight"`
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"silver"`
+		} `json:"generation-ii"`
+		GenerationIii struct {
+			Emerald struct {
+				FrontDefault string `json:"front_default"`
+				FrontShiny   string `json:"front_shiny"`
+			} `json:"emerald"`
+			FireredLeafgreen struct {
+				BackDefault  string `json:"back_default"`
+				BackShiny    string `json:"back_shiny"`
+				FrontDefault string `json:"front_default"`
+				FrontShiny   string `json:"front_shiny"`
+			} `json:"firered-leafgreen"`
+			RubySapphire struct {
+				BackDefault  string `json:"back_default"`
+				BackShiny    string `json:"back_shiny"`
+				FrontDefault string `json:"front_default"`
+				FrontShiny   string `json:"front_shiny"`
+			} `json:"ruby-sapphire"`
+		} `json:"generation-iii"`
+		GenerationIv struct {
+			DiamondPearl struct {
+				BackDefault      string  `json:"back_default"`
+				BackFemale       *string `json:"back_female"`
+				BackShiny        string  `json:"back_shiny"`
+				BackShinyFemale  *string `json:"back_shiny_female"`
+				FrontDefault     string  `json:"front_default"`
+				FrontFemale      *string `json:"front_female"`
+				FrontShiny       string  `json:"front_shiny"`
+				FrontShinyFemale *string `json:"front_shiny_female"`
+			} `json:"diamond-pearl"`
+			HeartgoldSoulsilver struct {
+				BackDefault      string  `json:"back_default"`
+				BackFemale       *string `json:"back_female"`
+				BackShiny        string  `json:"back_shiny"`
+				BackShinyFemale  *string `json:"back_shiny_female"`
+				FrontDefault     string  `json:"front_default"`
+				FrontFemale      *string `json:"front_female"`
+				FrontShiny       string  `json:"front_shiny"`
+				FrontShinyFemale *string `json:"front_shiny_female"`
+			} `json:"heartgold-soulsilver"`
+			Platinum struct {
+				BackDefault      string  `json:"back_default"`
+				BackFemale       *string `json:"back_female"`
+				BackShiny        string  `json:"back_shiny"`
+				BackShinyFemale  *string `json:"back_shiny_female"`
+				FrontDefault     string  `json:"front_default"`
+				FrontFemale      *string `json:"front_female"`
+				FrontShiny       string  `json:"front_shiny"`
+				FrontShinyFemale *string `json:"front_shiny_female"`
+			} `json:"platinum"`
+		} `json:"generation-iv"`
+		GenerationV struct {
+			BlackWhite struct {
+				Animated struct {
+					BackDefault      string  `json:"back_default"`
+					BackFemale       *string `json:"back_female"`
+					BackShiny        string  `json:"back_shiny"`
+					BackShinyFemale  *string `json:"back_shiny_female"`
+					FrontDefault     string  `json:"front_default"`
+					FrontFemale      *string `json:"front_female"`
+					FrontShiny       string  `json:"front_shiny"`
+					FrontShinyFemale *string `json:"front_shiny_female"`
+				} `json:"animated"`
+				BackDefault      string  `json:"back_default"`
+				BackFemale       *string `json:"back_female"`
+				BackShiny        string  `json:"back_shiny"`
+				BackShinyFemale  *string `json:"back_shiny_female"`
+				FrontDefault     string  `json:"front_default"`
+				FrontFemale      *string `json:"front_female"`
+				FrontShiny       string  `json:"front_shiny"`
+				FrontShinyFemale *string `json:"front_shiny_female"`
+			} `json:"black-white"`
+		} `json:"generation-v"`
+		GenerationVi struct {
+			OmegarubyAlphasapphire struct {
+				FrontDefault     string  `json:"front_default"`
+				FrontFemale      *string `json:"front_female"`
+				FrontShiny       string  `json:"front_shiny"`
+				FrontShinyFemale *string `json:"front_shiny_female"`
+			} `json:"omegaruby-alphasapphire"`
+			XY struct {
+				FrontDefault     string  `json:"front_default"`
+				FrontFemale      *string `json:"front_female"`
+				FrontShiny       string  `json:"front_shiny"`
+				FrontShinyFemale *string `json:"front_shiny_female"`
+			} `json:"x-y"`
+		} `json:"generation-vi"`
+		GenerationVii struct {
+			Icons struct {
+				FrontDefault string  `json:"front_default"`
+				FrontFemale  *string `json:"front_female"`
+			} `json:"icons"`
+			UltraSunUltraMoon struct {
+				FrontDefault     string  `json:"front_default"`
+				FrontFemale      *string `json:"front_female"`
+				FrontShiny       string  `json:"front_shiny"`
+				FrontShinyFemale *string `json:"front_shiny_female"`
+			} `json:"ultra-sun-ultra-moon"`
+		} `json:"generation-vii"`
+		GenerationViii struct {
+			Icons struct {
+				FrontDefault string  `json:"front_default"`
+				FrontFemale  *string `json:"front_female"`
+			} `json:"icons"`
+		} `json:"generation-viii"`
+	} `json:"versions"`
+}
+
+// PokemonSpecies is what all the forms of a pokemon have in common, like
+// its names and where it evolves from
+type PokemonSpecies struct {
+	ID                 int                                 `json:"id"`
+	Name               string                              `json:"name"`
+	Names              Names                               `json:"names"`
+	Order              int                                 `json:"order"`
+	CaptureRate        int                                 `json:"capture_rate"`
+	BaseHappiness      int                                 `json:"base_happiness"`
+	IsBaby             bool                                `json:"is_baby"`
+	IsLegendary        bool                                `json:"is_legendary"`
+	IsMythical         bool                                `json:"is_mythical"`
+	Generation         NamedAPIResource[LocalizedResource] `json:"generation"`
+	EvolvesFromSpecies *NamedAPIResource[PokemonSpecies]   `json:"evolves_from_species"`
+	Genera             []struct {
+		Genus    string                              `json:"genus"`
+		Language NamedAPIResource[LocalizedResource] `json:"language"`
+	} `json:"genera"`
+	Varieties []struct {
+		IsDefault bool                      `json:"is_default"`
+		Pokemon   NamedAPIResource[Pokemon] `json:"pokemon"`
+	} `json:"varieties"`
+}
+
+type Ability struct {
+	ID            int                                 `json:"id"`
+	Name          string                              `json:"name"`
+	Names         Names                               `json:"names"`
+	IsMainSeries  bool                                `json:"is_main_series"`
+	Generation    NamedAPIResource[LocalizedResource] `json:"generation"`
+	EffectEntries []struct {
+		Effect      string                              `json:"effect"`
+		ShortEffect string                              `json:"short_effect"`
+		Language    NamedAPIResource[LocalizedResource] `json:"language"`
+	} `json:"effect_entries"`
+	Pokemon []struct {
+		IsHidden bool                      `json:"is_hidden"`
+		Slot     int                       `json:"slot"`
+		Pokemon  NamedAPIResource[Pokemon] `json:"pokemon"`
+	} `json:"pokemon"`
+}
+
+type Move struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Names Names  `json:"names"`
+	// Accuracy, Power and PP are null for moves that don't have them
+	Accuracy    *int                                `json:"accuracy"`
+	Power       *int                                `json:"power"`
+	PP          *int                                `json:"pp"`
+	Priority    int                                 `json:"priority"`
+	Type        NamedAPIResource[Type]              `json:"type"`
+	DamageClass NamedAPIResource[LocalizedResource] `json:"damage_class"`
+}
+
+type Type struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Names           Names  `json:"names"`
+	DamageRelations struct {
+		DoubleDamageFrom []NamedAPIResource[Type] `json:"double_damage_from"`
+		DoubleDamageTo   []NamedAPIResource[Type] `json:"double_damage_to"`
+		HalfDamageFrom   []NamedAPIResource[Type] `json:"half_damage_from"`
+		HalfDamageTo     []NamedAPIResource[Type] `json:"half_damage_to"`
+		NoDamageFrom     []NamedAPIResource[Type] `json:"no_damage_from"`
+		NoDamageTo       []NamedAPIResource[Type] `json:"no_damage_to"`
+	} `json:"damage_relations"`
+}
+
+type Item struct {
+	ID       int                                 `json:"id"`
+	Name     string                              `json:"name"`
+	Names    Names                               `json:"names"`
+	Cost     int                                 `json:"cost"`
+	Category NamedAPIResource[LocalizedResource] `json:"category"`
+}
+
+// EncounterConditionValue is something that has to be true for an
+// encounter to happen, like "time-night" or "swarm-yes"
+type EncounterConditionValue struct {
+	ID        int                                 `json:"id"`
+	Name      string                              `json:"name"`
+	Names     Names                               `json:"names"`
+	Condition NamedAPIResource[LocalizedResource] `json:"condition"`
 }
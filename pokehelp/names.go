@@ -0,0 +1,240 @@
+package pokehelp
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The kinds of resources kept in the name index, these are also
+// the names of their list endpoints
+const (
+	KindPokemon      = "pokemon"
+	KindLocationArea = "location-area"
+	KindMove         = "move"
+	KindItem         = "item"
+)
+
+var indexKinds = []string{KindPokemon, KindLocationArea, KindMove, KindItem}
+
+// nameIndexMaxAge is how long the index saved on disk is trusted
+const nameIndexMaxAge = 7 * 24 * time.Hour
+
+// NameIndex holds the sorted names of every resource of each kind
+type NameIndex struct {
+	BuiltAt time.Time           `json:"built_at"`
+	Names   map[string][]string `json:"names"`
+}
+
+// GetNameIndex returns the name index, loading it from the user's cache
+// dir or building it from the list endpoints the first time it's needed
+func GetNameIndex(config *RequestConfig) (*NameIndex, error) {
+	if config.Index != nil {
+		return config.Index, nil
+	}
+
+	path := nameIndexPath()
+	if index, ok := loadNameIndex(path); ok && config.Endpoint(KindPokemon) == DefaultAPIURL+KindPokemon+"/" {
+		config.Index = index
+		return index, nil
+	}
+
+	index := &NameIndex{BuiltAt: time.Now(), Names: map[string][]string{}}
+	for _, kind := range indexKinds {
+		// Big enough limit to get everything in a single page
+		url := config.Endpoint(kind) + "?offset=0&limit=100000"
+
+		var list ResourceList
+		err := FetchInto(url, config, &list)
+		if errors.Is(err, ErrNotInSnapshot) {
+			// Go with whatever kinds the snapshot has
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(list.Results))
+		for _, v := range list.Results {
+			names = append(names, v.Name)
+		}
+		sort.Strings(names)
+		index.Names[kind] = names
+	}
+
+	// An offline index is only as complete as the snapshot, and one from
+	// another API (like the mock one) shouldn't stick around either
+	if !config.Offline && config.Endpoint(KindPokemon) == DefaultAPIURL+KindPokemon+"/" {
+		saveNameIndex(path, index)
+	}
+	config.Index = index
+
+	return index, nil
+}
+
+// ClearNameIndex throws out the name index, saved or not, so it's built
+// again from the API the next time it's needed
+func ClearNameIndex(config *RequestConfig) error {
+	config.Index = nil
+	path := nameIndexPath()
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func nameIndexPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "names.json")
+}
+
+func loadNameIndex(path string) (*NameIndex, bool) {
+	if path == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var index NameIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, false
+	}
+	if time.Since(index.BuiltAt) > nameIndexMaxAge {
+		return nil, false
+	}
+
+	return &index, true
+}
+
+// saveNameIndex writes the index to disk, failing to do so only means
+// it gets built again next time so errors are ignored
+func saveNameIndex(path string, index *NameIndex) {
+	if path == "" {
+		return
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0o644)
+}
+
+// Has checks if name is a known resource of kind
+func (n *NameIndex) Has(kind, name string) bool {
+	names := n.Names[kind]
+	i := sort.SearchStrings(names, name)
+	return i < len(names) && names[i] == name
+}
+
+// Complete returns the names of kind starting with prefix
+func (n *NameIndex) Complete(kind, prefix string) []string {
+	return CompleteFrom(n.Names[kind], prefix)
+}
+
+// Suggest returns up to max names of kind that look like name, closest first
+func (n *NameIndex) Suggest(kind, name string, max int) []string {
+	return SuggestFrom(n.Names[kind], name, max)
+}
+
+// CompleteFrom returns the sorted names starting with prefix
+func CompleteFrom(names []string, prefix string) []string {
+	matches := []string{}
+	for _, v := range names {
+		if strings.HasPrefix(v, prefix) {
+			matches = append(matches, v)
+		}
+	}
+	sort.Strings(matches)
+
+	return matches
+}
+
+// SuggestFrom returns up to max of the names within a few typos of name,
+// names that contain it count as close too
+func SuggestFrom(names []string, name string, max int) []string {
+	type match struct {
+		name     string
+		distance int
+		prefix   int
+	}
+
+	// Allow roughly one typo for every three letters
+	allowed := len(name)/3 + 1
+
+	matches := []match{}
+	for _, v := range names {
+		d := editDistance(name, v)
+		if len(name) > 2 && strings.Contains(v, name) {
+			d = min(d, 1)
+		}
+		if d <= allowed {
+			matches = append(matches, match{v, d, len(commonPrefix(name, v))})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		// Typos tend to be later in the word, so prefer a longer shared start
+		if matches[i].prefix != matches[j].prefix {
+			return matches[i].prefix > matches[j].prefix
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := []string{}
+	for i := 0; i < len(matches) && i < max; i++ {
+		suggestions = append(suggestions, matches[i].name)
+	}
+
+	return suggestions
+}
+
+func commonPrefix(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return a[:i]
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
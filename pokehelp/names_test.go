@@ -0,0 +1,34 @@
+package pokehelp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggestFrom(t *testing.T) {
+	names := []string{"pichu", "pikachu", "raichu", "bulbasaur", "canalave-city-area"}
+
+	if got := SuggestFrom(names, "pikchu", 3); len(got) == 0 || got[0] != "pikachu" {
+		t.Errorf("expected pikachu first but got %v", got)
+	}
+
+	if got := SuggestFrom(names, "canalave", 3); !reflect.DeepEqual(got, []string{"canalave-city-area"}) {
+		t.Errorf("expected the area containing the name but got %v", got)
+	}
+
+	if got := SuggestFrom(names, "zzz", 3); len(got) != 0 {
+		t.Errorf("expected no suggestions but got %v", got)
+	}
+}
+
+func TestNameIndexHasAndComplete(t *testing.T) {
+	index := &NameIndex{Names: map[string][]string{KindPokemon: {"pichu", "pidgey", "pikachu"}}}
+
+	if !index.Has(KindPokemon, "pidgey") || index.Has(KindPokemon, "pidge") {
+		t.Errorf("expected only exact names to be known")
+	}
+
+	if got := index.Complete(KindPokemon, "pi"); len(got) != 3 {
+		t.Errorf("expected 3 completions but got %v", got)
+	}
+}
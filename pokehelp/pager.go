@@ -0,0 +1,76 @@
+package pokehelp
+
+import "fmt"
+
+// DefaultPageSize is how many location areas `map` shows at once
+const DefaultPageSize = 20
+
+// Pager keeps track of which page of a list endpoint is being shown
+type Pager struct {
+	// Offset of the first item of the current page
+	Offset int
+	// Limit is the page size
+	Limit int
+	// Count is the total number of items, known after the first fetch
+	Count int
+	// Shown is false until the first page was fetched
+	Shown bool
+}
+
+func NewPager(limit int) *Pager {
+	return &Pager{Limit: limit}
+}
+
+// Page is the 1-based number of the current page
+func (p *Pager) Page() int {
+	return p.Offset/p.Limit + 1
+}
+
+// Pages is the total number of pages, 0 if nothing was fetched yet
+func (p *Pager) Pages() int {
+	return (p.Count + p.Limit - 1) / p.Limit
+}
+
+// URL builds the url to fetch the current page from baseUrl
+func (p *Pager) URL(baseUrl string) string {
+	return fmt.Sprintf("%s?offset=%d&limit=%d", baseUrl, p.Offset, p.Limit)
+}
+
+// GoTo moves to the 1-based page number, it doesn't check for an upper
+// bound since the count isn't known before the first fetch. Pages before
+// the first are the first, like Pages() of an empty list.
+func (p *Pager) GoTo(page int) {
+	p.Offset = (max(page, 1) - 1) * p.Limit
+}
+
+// SetLimit changes the page size, staying on the page that holds
+// the current first item
+func (p *Pager) SetLimit(limit int) {
+	p.Limit = limit
+	p.Offset = p.Offset / limit * limit
+}
+
+// Next moves forward a page, false if already on the last one
+func (p *Pager) Next() bool {
+	if !p.Shown {
+		p.Offset = 0
+		return true
+	}
+	if p.Offset+p.Limit >= p.Count {
+		return false
+	}
+	p.Offset += p.Limit
+	return true
+}
+
+// Prev moves back a page, false if already on the first one
+func (p *Pager) Prev() bool {
+	if !p.Shown || p.Offset == 0 {
+		return false
+	}
+	p.Offset -= p.Limit
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	return true
+}
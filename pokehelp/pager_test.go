@@ -0,0 +1,75 @@
+package pokehelp
+
+import "testing"
+
+func TestPagerNextPrev(t *testing.T) {
+	pager := NewPager(20)
+
+	if pager.Prev() {
+		t.Errorf("expected no previous page before the first fetch")
+	}
+
+	if !pager.Next() || pager.Offset != 0 {
+		t.Errorf("expected first page at offset 0 but got %d", pager.Offset)
+	}
+	pager.Shown = true
+	pager.Count = 45
+
+	pager.Next()
+	pager.Next()
+	if pager.Page() != 3 || pager.Pages() != 3 {
+		t.Errorf("expected page 3 of 3 but got %d of %d", pager.Page(), pager.Pages())
+	}
+
+	if pager.Next() {
+		t.Errorf("expected no next page after the last one")
+	}
+
+	pager.Prev()
+	if pager.Offset != 20 {
+		t.Errorf("expected offset 20 but got %d", pager.Offset)
+	}
+}
+
+func TestPagerSetLimit(t *testing.T) {
+	pager := NewPager(20)
+	pager.GoTo(4)
+
+	pager.SetLimit(50)
+	if pager.Offset != 50 || pager.Page() != 2 {
+		t.Errorf("expected offset 50 on page 2 but got %d on page %d", pager.Offset, pager.Page())
+	}
+
+	if url := pager.URL("https://pokeapi.co/api/v2/location-area/"); url != "https://pokeapi.co/api/v2/location-area/?offset=50&limit=50" {
+		t.Errorf("unexpected url %s", url)
+	}
+}
+
+func TestPagerGoTo(t *testing.T) {
+	for _, tt := range []struct {
+		count  int
+		page   int
+		offset int
+		pages  int
+	}{
+		{count: 45, page: 1, offset: 0, pages: 3},
+		{count: 45, page: 3, offset: 40, pages: 3},
+		{count: 40, page: 2, offset: 20, pages: 2},
+		{count: 45, page: -1, offset: 0, pages: 3},
+		{count: 0, page: 0, offset: 0, pages: 0},
+	} {
+		pager := NewPager(20)
+		pager.Count = tt.count
+		pager.GoTo(tt.page)
+		if pager.Offset != tt.offset || pager.Pages() != tt.pages {
+			t.Errorf("page %d of %d items: expected offset %d of %d pages but got %d of %d", tt.page, tt.count, tt.offset, tt.pages, pager.Offset, pager.Pages())
+		}
+	}
+
+	// Going to the last page of an empty list stays on the first
+	pager := NewPager(20)
+	pager.GoTo(pager.Pages())
+	if url := pager.URL("https://pokeapi.co/api/v2/location-area/"); url != "https://pokeapi.co/api/v2/location-area/?offset=0&limit=20" {
+		t.Errorf("unexpected url %s", url)
+	}
+}
@@ -0,0 +1,137 @@
+package pokehelp
+
+import (
+	"sort"
+	"time"
+)
+
+// PokemonSummary is the part of a pokemon the CLI keeps around, like in
+// the Pokedex. Moves and sprites are most of a pokemon's JSON and only a
+// few commands look at them, so they are left in the cache and decoded
+// from there when asked for.
+type PokemonSummary struct {
+	ID             int                              `json:"id"`
+	Name           string                           `json:"name"`
+	BaseExperience int                              `json:"base_experience"`
+	Height         int                              `json:"height"`
+	Weight         int                              `json:"weight"`
+	Species        NamedAPIResource[PokemonSpecies] `json:"species"`
+	Abilities      []PokemonAbility                 `json:"abilities"`
+	HeldItems      []PokemonHeldItem                `json:"held_items"`
+	Stats          []PokemonStat                    `json:"stats"`
+	Types          []PokemonType                    `json:"types"`
+}
+
+// url is where the pokemon's JSON is cached, by name like `catch` gets it
+func (p *PokemonSummary) url(config *RequestConfig) string {
+	return config.Endpoint(KindPokemon) + p.Name
+}
+
+// partURL is where a part of the pokemon is cached. REST answers with
+// the whole pokemon at once, GraphQL is asked for the part on its own
+// with the part as the fragment of the url.
+func (p *PokemonSummary) partURL(config *RequestConfig, part string) string {
+	if _, ok := config.Backend.(*GraphQLBackend); ok && !config.Offline {
+		return p.url(config) + "#" + part
+	}
+	return p.url(config)
+}
+
+// Moves decodes just the moves of the pokemon, fetching it again if it
+// has dropped out of the cache
+func (p *PokemonSummary) Moves(config *RequestConfig) ([]PokemonMove, error) {
+	var res struct {
+		Moves []PokemonMove `json:"moves"`
+	}
+	if err := FetchInto(p.partURL(config, PartMoves), config, &res); err != nil {
+		return nil, err
+	}
+	return res.Moves, nil
+}
+
+// Sprites decodes just the sprites of the pokemon, like Moves
+func (p *PokemonSummary) Sprites(config *RequestConfig) (*PokemonSprites, error) {
+	var res struct {
+		Sprites PokemonSprites `json:"sprites"`
+	}
+	if err := FetchInto(p.partURL(config, PartSprites), config, &res); err != nil {
+		return nil, err
+	}
+	return &res.Sprites, nil
+}
+
+// CaughtPokemon is a pokemon in the Pokedex, with what happened when it
+// was caught
+type CaughtPokemon struct {
+	PokemonSummary
+	Nickname string `json:"nickname,omitempty"`
+	Level    int    `json:"level"`
+	// CaughtAt is when, CaughtIn the location area and Game the version
+	// picked with `game` at the time, both empty if there wasn't one
+	CaughtAt time.Time `json:"caught_at"`
+	CaughtIn string    `json:"caught_in,omitempty"`
+	Game     string    `json:"game,omitempty"`
+
+	// What a team builder sets, empty for pokemon that were only caught.
+	// MoveSet are the moves it knows, KnownMoves when it's empty.
+	Item    string         `json:"item,omitempty"`
+	Ability string         `json:"ability,omitempty"`
+	Shiny   bool           `json:"shiny,omitempty"`
+	Nature  string         `json:"nature,omitempty"`
+	EVs     map[string]int `json:"evs,omitempty"`
+	IVs     map[string]int `json:"ivs,omitempty"`
+	MoveSet []string       `json:"move_set,omitempty"`
+}
+
+// DefaultAbility is the ability in the first slot, the one a caught
+// pokemon without one set is taken to have
+func (p *PokemonSummary) DefaultAbility() string {
+	for _, a := range p.Abilities {
+		if !a.IsHidden {
+			return a.Ability.Name
+		}
+	}
+	return ""
+}
+
+// KnownMoves are the moves the pokemon knows, its MoveSet or like in the
+// games the last four it learnt by leveling up to its level. versionGroup
+// picks whose learnsets, the latest are used when it's empty.
+func (c *CaughtPokemon) KnownMoves(config *RequestConfig, versionGroup string) ([]string, error) {
+	if len(c.MoveSet) > 0 {
+		return c.MoveSet, nil
+	}
+
+	moves, err := c.Moves(config)
+	if err != nil {
+		return nil, err
+	}
+
+	type learnt struct {
+		name  string
+		level int
+	}
+	var learnable []learnt
+	for _, m := range moves {
+		level := -1
+		for _, detail := range m.VersionGroupDetails {
+			if detail.MoveLearnMethod.Name != "level-up" || detail.LevelLearnedAt > c.Level {
+				continue
+			}
+			if versionGroup == "" || detail.VersionGroup.Name == versionGroup {
+				// Without a version group the last listed, the latest, wins
+				level = detail.LevelLearnedAt
+			}
+		}
+		if level >= 0 {
+			learnable = append(learnable, learnt{m.Move.Name, level})
+		}
+	}
+	sort.SliceStable(learnable, func(i, j int) bool { return learnable[i].level < learnable[j].level })
+
+	known := []string{}
+	for _, m := range learnable[max(len(learnable)-4, 0):] {
+		known = append(known, m.name)
+	}
+	return known, nil
+}
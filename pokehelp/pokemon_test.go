@@ -0,0 +1,53 @@
+package pokehelp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/munanadi/pokedex/pokecache"
+)
+
+// backendFunc answers every url with the same function
+type backendFunc func(url string) ([]byte, error)
+
+func (f backendFunc) Get(ctx context.Context, url string) ([]byte, error) {
+	return f(url)
+}
+
+func TestPokemonSummaryDecodesLazily(t *testing.T) {
+	fetches := 0
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Stop()
+
+	config := &RequestConfig{
+		Cache: cache,
+		Backend: backendFunc(func(url string) ([]byte, error) {
+			fetches++
+			return []byte(`{"id":25,"name":"pikachu","height":4,` +
+				`"moves":[{"move":{"name":"growl"},"version_group_details":[]}],` +
+				`"sprites":{"front_default":"pikachu.png"}}`), nil
+		}),
+	}
+
+	var summary PokemonSummary
+	if err := FetchInto(config.Endpoint(KindPokemon)+"pikachu", config, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Name != "pikachu" || summary.Height != 4 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	moves, err := summary.Moves(config)
+	if err != nil || len(moves) != 1 || moves[0].Move.Name != "growl" {
+		t.Errorf("expected growl but got %+v, %v", moves, err)
+	}
+	sprites, err := summary.Sprites(config)
+	if err != nil || sprites.FrontDefault != "pikachu.png" {
+		t.Errorf("expected the front sprite but got %+v, %v", sprites, err)
+	}
+
+	if fetches != 1 {
+		t.Errorf("expected moves and sprites to come from the cache but fetched %d times", fetches)
+	}
+}
@@ -0,0 +1,225 @@
+package pokehelp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProfileEnv picks the profile to start with when --profile isn't given
+const ProfileEnv = "POKEDEX_PROFILE"
+
+// DefaultProfile is the profile used when none is picked
+const DefaultProfile = "default"
+
+// ErrNoProfile is returned for profiles that haven't been created
+var ErrNoProfile = errors.New("no such profile")
+
+// Profile is a trainer's save: what they caught and saw, their team and
+// items, where they were and the language and game they picked. Other
+// settings are shared by every profile.
+type Profile struct {
+	Name    string                   `json:"name"`
+	Pokedex map[string]CaughtPokemon `json:"pokedex"`
+	Seen    map[string]SeenRecord    `json:"seen,omitempty"`
+	Team    []string                 `json:"team,omitempty"`
+	// Inventory is the count of each item by its name, like "poke-ball"
+	Inventory map[string]int `json:"inventory,omitempty"`
+	Region    string         `json:"region,omitempty"`
+	Area      string         `json:"area,omitempty"`
+	Lang      string         `json:"lang,omitempty"`
+	Game      string         `json:"game,omitempty"`
+}
+
+// Profiles is the Storage that keeps every profile as a json file in Dir
+type Profiles struct {
+	Dir string
+}
+
+// DefaultProfilesDir is pokedex/profiles in the user config dir, next to
+// the history
+func DefaultProfilesDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "profiles")
+}
+
+func NewProfiles(dir string) *Profiles {
+	return &Profiles{Dir: dir}
+}
+
+var profileName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,31}$`)
+
+// ValidProfileName says if name can be used as a profile, it ends up as a
+// file name so only letters, digits, - and _ are allowed
+func ValidProfileName(name string) bool {
+	return profileName.MatchString(name)
+}
+
+func (p *Profiles) path(name string) string {
+	return filepath.Join(p.Dir, name+".json")
+}
+
+// List is the names of the saved profiles, sorted
+func (p *Profiles) List() ([]string, error) {
+	files, err := os.ReadDir(p.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if name, ok := strings.CutSuffix(f.Name(), ".json"); ok && !f.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Exists says if the profile has been saved
+func (p *Profiles) Exists(name string) bool {
+	_, err := os.Stat(p.path(name))
+	return err == nil
+}
+
+// Load reads a saved profile, ErrNoProfile if it was never saved
+func (p *Profiles) Load(name string) (*Profile, error) {
+	if !ValidProfileName(name) {
+		return nil, fmt.Errorf("%q isn't a valid profile name", name)
+	}
+
+	data, err := os.ReadFile(p.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("loading profile %s: %w", name, ErrNoProfile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("loading profile %s: %w", name, err)
+	}
+	profile.Name = name
+	if profile.Pokedex == nil {
+		profile.Pokedex = map[string]CaughtPokemon{}
+	}
+	return &profile, nil
+}
+
+// Save writes the profile, through a temporary file so a crash halfway
+// doesn't leave it corrupted
+func (p *Profiles) Save(profile *Profile) error {
+	if !ValidProfileName(profile.Name) {
+		return fmt.Errorf("%q isn't a valid profile name", profile.Name)
+	}
+	if err := os.MkdirAll(p.Dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(p.Dir, profile.Name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p.path(profile.Name))
+}
+
+// QueryCaught loads the profile and filters its pokedex in memory, json
+// files don't have indexes
+func (p *Profiles) QueryCaught(name string, filter PokedexFilter) ([]CaughtPokemon, error) {
+	profile, err := p.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return FilterCaught(profile.Pokedex, filter), nil
+}
+
+// Delete removes a saved profile
+func (p *Profiles) Delete(name string) error {
+	err := os.Remove(p.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("deleting profile %s: %w", name, ErrNoProfile)
+	}
+	return err
+}
+
+// NewProfile is an empty profile for a new trainer
+func NewProfile(name string) *Profile {
+	return &Profile{Name: name, Pokedex: map[string]CaughtPokemon{}}
+}
+
+// CurrentProfile is the state of the session as the profile it belongs to
+func (c *RequestConfig) CurrentProfile() *Profile {
+	profile := &Profile{
+		Name:      c.Profile,
+		Pokedex:   c.Pokedex,
+		Seen:      c.Seen,
+		Team:      c.Team,
+		Inventory: c.Inventory,
+		Region:    c.Region,
+		Area:      c.Area,
+		Lang:      c.Lang,
+	}
+	if c.Game != nil {
+		profile.Game = c.Game.Name
+	}
+	return profile
+}
+
+// UseProfile makes profile the one the session plays as. Settings it
+// doesn't have come from Settings, and the environment and flags win over
+// the ones it has.
+func (c *RequestConfig) UseProfile(profile *Profile) {
+	c.Profile = profile.Name
+	c.Pokedex = profile.Pokedex
+	c.Seen = profile.Seen
+	c.Team = profile.Team
+	c.Inventory = profile.Inventory
+	c.Region = profile.Region
+	c.Area = profile.Area
+	c.Lang = c.Settings.String("lang")
+	if profile.Lang != "" && !c.Settings.Overridden("lang") {
+		c.Lang = profile.Lang
+	}
+	game := c.Settings.String("game")
+	if profile.Game != "" && !c.Settings.Overridden("game") {
+		game = profile.Game
+	}
+	c.Game = nil
+	if g, ok := LookupGameVersion(game); ok {
+		c.Game = &g
+	}
+}
+
+// SaveProfile saves the session to its profile, sessions without
+// Storage, like in tests, aren't saved
+func (c *RequestConfig) SaveProfile() error {
+	if c.Storage == nil || c.Profile == "" {
+		return nil
+	}
+	return c.Storage.Save(c.CurrentProfile())
+}
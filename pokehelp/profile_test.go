@@ -0,0 +1,72 @@
+package pokehelp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProfilesSaveLoad(t *testing.T) {
+	profiles := NewProfiles(t.TempDir())
+
+	names, err := profiles.List()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("expected no profiles but got %v %v", names, err)
+	}
+	if _, err := profiles.Load("ash"); !errors.Is(err, ErrNoProfile) {
+		t.Errorf("expected ErrNoProfile but got %v", err)
+	}
+
+	caughtAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ash := &Profile{
+		Name:      "ash",
+		Pokedex:   map[string]CaughtPokemon{"pikachu": {PokemonSummary: PokemonSummary{ID: 25, Name: "pikachu"}, Level: 5, CaughtAt: caughtAt}},
+		Team:      []string{"pikachu"},
+		Inventory: map[string]int{"poke-ball": 5, "potion": 2},
+		Region:    "kanto",
+		Area:      "viridian-forest-area",
+		Lang:      "ja",
+		Game:      "yellow",
+	}
+	for _, profile := range []*Profile{ash, NewProfile("misty")} {
+		if err := profiles.Save(profile); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := profiles.Load("ash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, ash) {
+		t.Errorf("expected %+v but got %+v", ash, loaded)
+	}
+
+	if err := profiles.Delete("misty"); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := profiles.List(); !reflect.DeepEqual(names, []string{"ash"}) {
+		t.Errorf("expected only ash to be left but got %v", names)
+	}
+	if err := profiles.Delete("misty"); !errors.Is(err, ErrNoProfile) {
+		t.Errorf("expected ErrNoProfile deleting twice but got %v", err)
+	}
+
+	if err := profiles.Save(&Profile{Name: "../escape"}); err == nil {
+		t.Errorf("expected a name with a path in it to be refused")
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	config := &RequestConfig{Lang: "en"}
+	profile := &Profile{Name: "ash", Pokedex: map[string]CaughtPokemon{}, Team: []string{"pikachu"}, Inventory: map[string]int{"poke-ball": 5}, Area: "viridian-forest-area", Lang: "ja", Game: "yellow"}
+
+	config.UseProfile(profile)
+	if config.Profile != "ash" || config.Lang != "ja" || config.Game == nil || config.Game.VersionGroup != "yellow" {
+		t.Errorf("profile wasn't applied: %+v", config)
+	}
+	if got := config.CurrentProfile(); !reflect.DeepEqual(got, profile) {
+		t.Errorf("expected %+v back but got %+v", profile, got)
+	}
+}
@@ -0,0 +1,72 @@
+package pokehelp
+
+import (
+	"context"
+	"sync"
+)
+
+// NamedAPIResource is a link to another resource of type T, like the
+// species of a pokemon, that Resolve follows. Links to resources the
+// CLI only ever shows the name of use LocalizedResource as T.
+type NamedAPIResource[T any] struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Fetcher gets the JSON of an API url, RequestConfig does that through
+// the cache with its client or snapshot
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// Resolve fetches the resource the link points at
+func (r NamedAPIResource[T]) Resolve(ctx context.Context, client Fetcher) (*T, error) {
+	data, err := client.Fetch(ctx, r.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var v T
+	if err := jsonUnmarshal(r.URL, data, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// LocalizedName is the name of the linked resource in the configured
+// language, see LocalizedNameFromUrl
+func (r NamedAPIResource[T]) LocalizedName(config *RequestConfig) string {
+	return LocalizedNameFromUrl(r.URL, r.Name, config)
+}
+
+// ResolveAll resolves every link in parallel, the results are in the
+// same order as refs
+func ResolveAll[T any](ctx context.Context, client Fetcher, refs []NamedAPIResource[T]) ([]*T, error) {
+	res := make([]*T, len(refs))
+	errs := make([]error, len(refs))
+
+	var wg sync.WaitGroup
+	for i := range refs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res[i], errs[i] = refs[i].Resolve(ctx, client)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Fetch gets url through the cache like FetchInto, with ctx instead of
+// the running command's context
+func (c *RequestConfig) Fetch(ctx context.Context, url string) ([]byte, error) {
+	withCtx := *c
+	withCtx.Ctx = ctx
+	return GetCachedBodyFromUrl(url, &withCtx)
+}
@@ -0,0 +1,52 @@
+package pokehelp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/munanadi/pokedex/pokecache"
+)
+
+func TestResolveWalksFromPokemon(t *testing.T) {
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Stop()
+
+	resources := map[string]string{
+		"pokemon/25/":         `{"id":25,"name":"pikachu","species":{"name":"pikachu","url":"https://pokeapi.co/api/v2/pokemon-species/25/"},"types":[{"slot":1,"type":{"name":"electric","url":"https://pokeapi.co/api/v2/type/13/"}}]}`,
+		"pokemon-species/25/": `{"id":25,"name":"pikachu","evolves_from_species":{"name":"pichu","url":"https://pokeapi.co/api/v2/pokemon-species/172/"}}`,
+		"type/13/":            `{"id":13,"name":"electric","damage_relations":{"double_damage_to":[{"name":"water","url":"https://pokeapi.co/api/v2/type/11/"}]}}`,
+	}
+	config := &RequestConfig{
+		Cache: cache,
+		Backend: backendFunc(func(url string) ([]byte, error) {
+			if data, ok := resources[strings.TrimPrefix(url, DefaultAPIURL)]; ok {
+				return []byte(data), nil
+			}
+			return nil, fmt.Errorf("fetching %s: %w", url, ErrNotFound)
+		}),
+	}
+	ctx := context.Background()
+
+	pokemon, err := NamedAPIResource[Pokemon]{Name: "pikachu", URL: DefaultAPIURL + "pokemon/25/"}.Resolve(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	species, err := pokemon.Species.Resolve(ctx, config)
+	if err != nil || species.EvolvesFromSpecies == nil || species.EvolvesFromSpecies.Name != "pichu" {
+		t.Errorf("expected pikachu to evolve from pichu but got %+v, %v", species, err)
+	}
+
+	types, err := ResolveAll(ctx, config, []NamedAPIResource[Type]{pokemon.Types[0].Type})
+	if err != nil || len(types) != 1 || types[0].DamageRelations.DoubleDamageTo[0].Name != "water" {
+		t.Errorf("expected electric to beat water but got %+v, %v", types, err)
+	}
+
+	if _, err := species.EvolvesFromSpecies.Resolve(ctx, config); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected pichu to be missing but got %v", err)
+	}
+}
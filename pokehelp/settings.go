@@ -0,0 +1,275 @@
+package pokehelp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/munanadi/pokedex/pokelang"
+	"github.com/munanadi/pokedex/pokesprite"
+)
+
+// Source is where the value of a setting came from. Later ones win:
+// defaults, the config file, the environment, then flags.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Setting is something that can be set in the config file, with an
+// environment variable or a flag of the same name
+type Setting struct {
+	Key     string
+	Env     string
+	Flag    string
+	Default string
+	Usage   string
+	// Int settings are written to the file as numbers
+	Int      bool
+	validate func(value string) error
+}
+
+// Colors are the values of the color setting: auto guesses what the
+// terminal can do, never doesn't draw sprites, the rest are the modes of
+// pokesprite
+var Colors = []string{"auto", "never", "256", "truecolor", "kitty", "sixel"}
+
+// AllSettings are the settings there are, in the order `config show`
+// lists them
+var AllSettings = []Setting{
+	{Key: "cache_ttl", Env: "POKEDEX_CACHE_TTL", Flag: "cache-ttl", Default: "30s", Usage: "how long responses are served from the cache, like 30s or 5m",
+		validate: func(v string) error {
+			if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+				return fmt.Errorf("%q isn't a duration like 30s or 5m", v)
+			}
+			return nil
+		}},
+	{Key: "api_url", Env: "POKEDEX_API_URL", Flag: "api-url", Default: DefaultAPIURL, Usage: "base url of the API, like the one `serve-mock` prints",
+		validate: func(v string) error {
+			if u, err := url.Parse(v); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("%q isn't an http url", v)
+			}
+			return nil
+		}},
+	{Key: "page_size", Env: "POKEDEX_PAGE_SIZE", Flag: "page-size", Default: strconv.Itoa(DefaultPageSize), Usage: "how many location areas map shows at once", Int: true,
+		validate: func(v string) error {
+			if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+				return fmt.Errorf("%q isn't a positive number", v)
+			}
+			return nil
+		}},
+	{Key: "lang", Env: "POKEDEX_LANG", Flag: "lang", Default: pokelang.DefaultLang, Usage: "language for names and messages, one of " + strings.Join(pokelang.Supported(), ", "),
+		validate: oneOf(pokelang.Supported())},
+	{Key: "game", Env: "POKEDEX_GAME", Flag: "game", Default: "", Usage: "game version to filter by, like red or crystal",
+		validate: func(v string) error {
+			if _, ok := LookupGameVersion(v); !ok && v != "" {
+				return fmt.Errorf("%q isn't a game, use one of %s", v, strings.Join(GameVersionNames(), ", "))
+			}
+			return nil
+		}},
+	{Key: "export_format", Env: "POKEDEX_EXPORT_FORMAT", Flag: "export-format", Default: FormatJSON, Usage: "format of export and import files whose extension doesn't say, one of " + strings.Join(ExportFormats, ", "),
+		validate: oneOf(ExportFormats)},
+	{Key: "color", Env: "POKEDEX_COLOR", Flag: "color", Default: "auto", Usage: "how sprites are drawn, one of " + strings.Join(Colors, ", "),
+		validate: func(v string) error {
+			if _, ok := pokesprite.ParseMode(v); !ok && v != "auto" && v != "never" {
+				return fmt.Errorf("%q isn't one of %s", v, strings.Join(Colors, ", "))
+			}
+			return nil
+		}},
+}
+
+func oneOf(values []string) func(string) error {
+	return func(v string) error {
+		if !slices.Contains(values, v) {
+			return fmt.Errorf("%q isn't one of %s", v, strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
+
+// LookupSetting finds a setting by its key
+func LookupSetting(key string) (Setting, bool) {
+	i := slices.IndexFunc(AllSettings, func(s Setting) bool { return s.Key == key })
+	if i < 0 {
+		return Setting{}, false
+	}
+	return AllSettings[i], true
+}
+
+// SettingKeys are the keys of all settings
+func SettingKeys() []string {
+	keys := make([]string, len(AllSettings))
+	for i, s := range AllSettings {
+		keys[i] = s.Key
+	}
+	return keys
+}
+
+// DefaultConfigPath is pokedex/config.toml in the user config dir
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "config.toml")
+}
+
+// Settings are the layered values of AllSettings. A nil *Settings has
+// the defaults, like in tests.
+type Settings struct {
+	// Path is the config file, `config set` writes to it
+	Path    string
+	values  map[string]string
+	sources map[string]Source
+	// file is what is in the config file, written back by Set
+	file map[string]string
+}
+
+// LoadSettings reads the config file at path, which doesn't have to
+// exist, and the environment from getenv over it. Flags are added with
+// SetFlag.
+func LoadSettings(path string, getenv func(string) string) (*Settings, error) {
+	s := &Settings{Path: path, values: map[string]string{}, sources: map[string]Source{}, file: map[string]string{}}
+	for _, setting := range AllSettings {
+		s.values[setting.Key], s.sources[setting.Key] = setting.Default, SourceDefault
+	}
+
+	if path != "" {
+		var raw map[string]any
+		_, err := toml.DecodeFile(path, &raw)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		for key, value := range raw {
+			v := fmt.Sprint(value)
+			if err := ValidateSetting(key, v); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", path, err)
+			}
+			s.file[key] = v
+			s.values[key], s.sources[key] = v, SourceFile
+		}
+	}
+
+	for _, setting := range AllSettings {
+		v := getenv(setting.Env)
+		if v == "" {
+			continue
+		}
+		if err := ValidateSetting(setting.Key, v); err != nil {
+			return nil, fmt.Errorf("$%s: %w", setting.Env, err)
+		}
+		s.values[setting.Key], s.sources[setting.Key] = v, SourceEnv
+	}
+	return s, nil
+}
+
+// ValidateSetting checks that key is a setting and value is one it can be
+// set to
+func ValidateSetting(key string, value string) error {
+	setting, ok := LookupSetting(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q, use one of %s", key, strings.Join(SettingKeys(), ", "))
+	}
+	if setting.validate != nil {
+		if err := setting.validate(value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SetFlag sets a value given on the command line, which wins over
+// everything else
+func (s *Settings) SetFlag(key string, value string) error {
+	if err := ValidateSetting(key, value); err != nil {
+		return err
+	}
+	s.values[key], s.sources[key] = value, SourceFlag
+	return nil
+}
+
+// Get is the value of a setting and where it came from
+func (s *Settings) Get(key string) (string, Source) {
+	if s == nil {
+		setting, _ := LookupSetting(key)
+		return setting.Default, SourceDefault
+	}
+	return s.values[key], s.sources[key]
+}
+
+// String is the value of a setting
+func (s *Settings) String(key string) string {
+	v, _ := s.Get(key)
+	return v
+}
+
+// Int is the value of an Int setting
+func (s *Settings) Int(key string) int {
+	n, _ := strconv.Atoi(s.String(key))
+	return n
+}
+
+// Duration is the value of a duration setting
+func (s *Settings) Duration(key string) time.Duration {
+	d, _ := time.ParseDuration(s.String(key))
+	return d
+}
+
+// Overridden says if the environment or a flag set the value, then the
+// config file and profiles don't change it
+func (s *Settings) Overridden(key string) bool {
+	_, source := s.Get(key)
+	return source == SourceEnv || source == SourceFlag
+}
+
+// Set writes value to the config file. The session gets the value too,
+// unless the environment or a flag overrides it.
+func (s *Settings) Set(key string, value string) error {
+	if err := ValidateSetting(key, value); err != nil {
+		return err
+	}
+	if s.Path == "" {
+		return errors.New("there's no config dir to write the config file to")
+	}
+
+	file := map[string]any{}
+	for k, v := range s.file {
+		file[k] = v
+	}
+	file[key] = value
+	for k, v := range file {
+		if setting, _ := LookupSetting(k); setting.Int {
+			file[k], _ = strconv.Atoi(v.(string))
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Written by `config set`, see `config show` for what can be set\n")
+	if err := toml.NewEncoder(&b).Encode(file); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.Path, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	s.file[key] = value
+	if !s.Overridden(key) {
+		s.values[key], s.sources[key] = value, SourceFile
+	}
+	return nil
+}
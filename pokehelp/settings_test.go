@@ -0,0 +1,118 @@
+package pokehelp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadSettingsPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("cache_ttl = \"5m\"\npage_size = 10\nlang = \"de\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"POKEDEX_PAGE_SIZE": "15", "POKEDEX_LANG": "fr"}
+
+	settings, err := LoadSettings(path, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := settings.SetFlag("lang", "ja"); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]struct {
+		value  string
+		source Source
+	}{
+		"export_format": {FormatJSON, SourceDefault},
+		"cache_ttl":     {"5m", SourceFile},
+		"page_size":     {"15", SourceEnv},
+		"lang":          {"ja", SourceFlag},
+	} {
+		if value, source := settings.Get(key); value != want.value || source != want.source {
+			t.Errorf("%s: expected %s from %s but got %s from %s", key, want.value, want.source, value, source)
+		}
+	}
+	if settings.Duration("cache_ttl") != 5*time.Minute || settings.Int("page_size") != 15 {
+		t.Errorf("unexpected typed values %v %v", settings.Duration("cache_ttl"), settings.Int("page_size"))
+	}
+}
+
+func TestLoadSettingsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, config := range map[string]string{
+		"unknown.toml": "colour = \"never\"\n",
+		"bad.toml":     "page_size = -1\n",
+		"broken.toml":  "lang = \n",
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(config), 0o644)
+		if _, err := LoadSettings(path, os.Getenv); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	env := func(key string) string {
+		if key == "POKEDEX_CACHE_TTL" {
+			return "soon"
+		}
+		return ""
+	}
+	if _, err := LoadSettings(filepath.Join(dir, "missing.toml"), env); err == nil || !strings.Contains(err.Error(), "POKEDEX_CACHE_TTL") {
+		t.Errorf("expected the environment variable in the error but got %v", err)
+	}
+}
+
+func TestSettingsSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex", "config.toml")
+	getenv := func(key string) string {
+		if key == "POKEDEX_LANG" {
+			return "fr"
+		}
+		return ""
+	}
+	settings, err := LoadSettings(path, getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]string{"page_size": "50", "color": "never", "lang": "ja"} {
+		if err := settings.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := settings.Set("page_size", "lots"); err == nil {
+		t.Errorf("expected an invalid value to be refused")
+	}
+	if value, source := settings.Get("lang"); value != "fr" || source != SourceEnv {
+		t.Errorf("expected the environment to still win but got %s from %s", value, source)
+	}
+
+	reloaded, err := LoadSettings(path, func(string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"page_size": "50", "color": "never", "lang": "ja"} {
+		if value, source := reloaded.Get(key); value != want || source != SourceFile {
+			t.Errorf("%s: expected %s from the file but got %s from %s", key, want, value, source)
+		}
+	}
+}
+
+func TestUseProfileWithSettings(t *testing.T) {
+	settings, err := LoadSettings("", func(key string) string {
+		return map[string]string{"POKEDEX_GAME": "crystal"}[key]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &RequestConfig{Settings: settings}
+
+	config.UseProfile(&Profile{Name: "ash", Lang: "ja", Game: "yellow"})
+	if config.Lang != "ja" || config.Game == nil || config.Game.Name != "crystal" {
+		t.Errorf("expected the profile's lang and $POKEDEX_GAME but got %s %+v", config.Lang, config.Game)
+	}
+}
@@ -0,0 +1,361 @@
+package pokehelp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TeamSize is how many pokemon a team has at most
+const TeamSize = 6
+
+// Natures are the 25 natures, Showdown assumes serious without one
+var Natures = []string{
+	"hardy", "lonely", "brave", "adamant", "naughty",
+	"bold", "docile", "relaxed", "impish", "lax",
+	"timid", "hasty", "serious", "jolly", "naive",
+	"modest", "mild", "quiet", "bashful", "rash",
+	"calm", "gentle", "sassy", "careful", "quirky",
+}
+
+// Limits on effort and individual values since gen 3
+const (
+	MaxEV      = 252
+	MaxTotalEV = 510
+	MaxIV      = 31
+)
+
+// showdownStats are how Showdown abbreviates StatNames
+var showdownStats = map[string]string{
+	"hp":              "HP",
+	"attack":          "Atk",
+	"defense":         "Def",
+	"special-attack":  "SpA",
+	"special-defense": "SpD",
+	"speed":           "Spe",
+}
+
+// ShowdownSet is a pokemon in a Pokemon Showdown team paste, like
+//
+//	Sparky (Pikachu) @ Light Ball
+//	Ability: Static
+//	Level: 50
+//	EVs: 252 SpA / 4 SpD / 252 Spe
+//	Timid Nature
+//	IVs: 0 Atk
+//	- Thunderbolt
+//	- Volt Switch
+//
+// Names are kept as the API's slugs, "light-ball" for "Light Ball".
+// Stats missing from IVs are 31, from EVs 0.
+type ShowdownSet struct {
+	Nickname string
+	Species  string
+	Item     string
+	Ability  string
+	Level    int
+	Shiny    bool
+	Nature   string
+	EVs      map[string]int
+	IVs      map[string]int
+	Moves    []string
+
+	// Row is where the set was in the paste it was read from
+	Row int
+}
+
+// NewShowdownSet is a caught pokemon as a set with the moves given
+func NewShowdownSet(c CaughtPokemon, moves []string) ShowdownSet {
+	set := ShowdownSet{
+		Nickname: c.Nickname,
+		Species:  c.Name,
+		Item:     c.Item,
+		Ability:  c.Ability,
+		Level:    c.Level,
+		Shiny:    c.Shiny,
+		Nature:   c.Nature,
+		EVs:      c.EVs,
+		IVs:      c.IVs,
+		Moves:    moves,
+	}
+	if set.Ability == "" {
+		set.Ability = c.DefaultAbility()
+	}
+	return set
+}
+
+// Caught is the set as a caught pokemon of the species in summary
+func (s *ShowdownSet) Caught(summary PokemonSummary) CaughtPokemon {
+	return CaughtPokemon{
+		PokemonSummary: summary,
+		Nickname:       s.Nickname,
+		Level:          s.Level,
+		Item:           s.Item,
+		Ability:        s.Ability,
+		Shiny:          s.Shiny,
+		Nature:         s.Nature,
+		EVs:            s.EVs,
+		IVs:            s.IVs,
+		MoveSet:        s.Moves,
+	}
+}
+
+// Validate checks the set against what the API has on the pokemon: the
+// ability has to be one it can have and the moves ones it can learn
+func (s *ShowdownSet) Validate(pokemon *PokemonSummary, moves []PokemonMove) error {
+	if s.Level < 1 || s.Level > 100 {
+		return fmt.Errorf("level has to be 1 to 100, not %d", s.Level)
+	}
+	if s.Nature != "" && !slices.Contains(Natures, s.Nature) {
+		return fmt.Errorf("unknown nature %q", s.Nature)
+	}
+
+	total := 0
+	for name, value := range s.EVs {
+		if value < 0 || value > MaxEV {
+			return fmt.Errorf("%s EVs have to be 0 to %d, not %d", showdownStats[name], MaxEV, value)
+		}
+		total += value
+	}
+	if total > MaxTotalEV {
+		return fmt.Errorf("EVs add up to %d, more than %d", total, MaxTotalEV)
+	}
+	for name, value := range s.IVs {
+		if value < 0 || value > MaxIV {
+			return fmt.Errorf("%s IVs have to be 0 to %d, not %d", showdownStats[name], MaxIV, value)
+		}
+	}
+
+	if s.Ability != "" && !slices.ContainsFunc(pokemon.Abilities, func(a PokemonAbility) bool { return a.Ability.Name == s.Ability }) {
+		return fmt.Errorf("%s can't have the ability %s", pokemon.Name, s.Ability)
+	}
+
+	if len(s.Moves) == 0 || len(s.Moves) > 4 {
+		return fmt.Errorf("a pokemon knows one to four moves, not %d", len(s.Moves))
+	}
+	for _, move := range s.Moves {
+		if !slices.ContainsFunc(moves, func(m PokemonMove) bool { return m.Move.Name == move }) {
+			return fmt.Errorf("%s can't learn %s", pokemon.Name, move)
+		}
+	}
+	return nil
+}
+
+// WriteShowdown writes the sets as a paste Showdown's teambuilder can import
+func WriteShowdown(w io.Writer, sets []ShowdownSet) error {
+	var b strings.Builder
+	for i, s := range sets {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		species := showdownName(s.Species, "-")
+		if s.Nickname != "" {
+			b.WriteString(s.Nickname + " (" + species + ")")
+		} else {
+			b.WriteString(species)
+		}
+		if s.Item != "" {
+			b.WriteString(" @ " + showdownName(s.Item, " "))
+		}
+		b.WriteString("\n")
+
+		if s.Ability != "" {
+			b.WriteString("Ability: " + showdownName(s.Ability, " ") + "\n")
+		}
+		if s.Level != 0 && s.Level != 100 {
+			fmt.Fprintf(&b, "Level: %d\n", s.Level)
+		}
+		if s.Shiny {
+			b.WriteString("Shiny: Yes\n")
+		}
+		if evs := showdownSpread(s.EVs, 0); evs != "" {
+			b.WriteString("EVs: " + evs + "\n")
+		}
+		if s.Nature != "" {
+			b.WriteString(showdownName(s.Nature, " ") + " Nature\n")
+		}
+		if ivs := showdownSpread(s.IVs, MaxIV); ivs != "" {
+			b.WriteString("IVs: " + ivs + "\n")
+		}
+		for _, move := range s.Moves {
+			b.WriteString("- " + showdownName(move, " ") + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReadShowdown reads the sets of a paste. Sets that can't be read are
+// left out and returned as ImportErrors with Row counting the sets.
+func ReadShowdown(r io.Reader) ([]ShowdownSet, []ImportError, error) {
+	var sets []ShowdownSet
+	var bad []ImportError
+	var lines []string
+	n := 0
+
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+		n++
+		set, err := parseShowdownSet(lines)
+		if err != nil {
+			bad = append(bad, ImportError{Row: n, Err: err})
+		} else {
+			set.Row = n
+			sets = append(sets, set)
+		}
+		lines = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "===") && strings.HasSuffix(line, "==="):
+			// "=== [gen9ou] Team name ===" starts a team in a paste of several
+			flush()
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("reading paste: %w", err)
+	}
+	flush()
+
+	return sets, bad, nil
+}
+
+func parseShowdownSet(lines []string) (ShowdownSet, error) {
+	set := ShowdownSet{Level: 100, EVs: map[string]int{}, IVs: map[string]int{}}
+	for _, name := range StatNames {
+		set.IVs[name] = MaxIV
+	}
+
+	// Nickname (Species) (F) @ Item
+	head := lines[0]
+	if name, item, ok := strings.Cut(head, " @ "); ok {
+		head, set.Item = name, ToSlug(item)
+	}
+	head = strings.TrimSuffix(strings.TrimSuffix(head, " (M)"), " (F)")
+	if open := strings.LastIndex(head, " ("); open > 0 && strings.HasSuffix(head, ")") {
+		set.Nickname, head = head[:open], head[open+2:len(head)-1]
+	}
+	set.Species = ToSlug(head)
+	if set.Species == "" {
+		return set, errors.New("no species")
+	}
+
+	for _, line := range lines[1:] {
+		key, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch {
+		case strings.HasPrefix(line, "-"):
+			set.Moves = append(set.Moves, ToSlug(strings.TrimPrefix(line, "-")))
+		case strings.HasSuffix(line, " Nature"):
+			set.Nature = ToSlug(strings.TrimSuffix(line, " Nature"))
+		case key == "Ability":
+			set.Ability = ToSlug(value)
+		case key == "Level":
+			if set.Level, err = strconv.Atoi(value); err != nil {
+				err = fmt.Errorf("level %q isn't a number", value)
+			}
+		case key == "Shiny":
+			set.Shiny = strings.EqualFold(value, "yes")
+		case key == "EVs":
+			err = parseSpread(value, set.EVs)
+		case key == "IVs":
+			err = parseSpread(value, set.IVs)
+		case slices.Contains([]string{"Happiness", "Tera Type", "Gigantamax", "Dynamax Level", "Hidden Power", "Pokeball"}, key):
+			// Showdown has these, the API doesn't
+		default:
+			err = fmt.Errorf("can't read %q", line)
+		}
+		if err != nil {
+			return set, err
+		}
+	}
+	return set, nil
+}
+
+// parseSpread reads "252 SpA / 4 SpD / 252 Spe" into values
+func parseSpread(spread string, values map[string]int) error {
+	for _, part := range strings.Split(spread, "/") {
+		number, abbrev, _ := strings.Cut(strings.TrimSpace(part), " ")
+		stat := ""
+		for name, a := range showdownStats {
+			if strings.EqualFold(a, abbrev) {
+				stat = name
+			}
+		}
+		if stat == "" {
+			return fmt.Errorf("unknown stat %q", abbrev)
+		}
+
+		value, err := strconv.Atoi(number)
+		if err != nil {
+			return fmt.Errorf("%s %q isn't a number", abbrev, number)
+		}
+		values[stat] = value
+	}
+	return nil
+}
+
+// showdownSpread is the stats that aren't at def, as "252 SpA / 4 SpD"
+func showdownSpread(values map[string]int, def int) string {
+	var parts []string
+	for _, name := range StatNames {
+		if value, ok := values[name]; ok && value != def {
+			parts = append(parts, fmt.Sprintf("%d %s", value, showdownStats[name]))
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// showdownName is how Showdown writes a slug, "Thunder Punch" for
+// "thunder-punch" with a space or "Mr-Mime" for "mr-mime" with a dash.
+// Showdown only compares the letters and digits of names, so both work.
+func showdownName(slug string, sep string) string {
+	words := strings.Split(slug, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, sep)
+}
+
+// ToSlug turns a name like "Mr. Mime" or "Farfetch’d" into the API's
+// "mr-mime" and "farfetchd"
+func ToSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			dash = true
+		case r == '♀':
+			b.WriteString("-f")
+		case r == '♂':
+			b.WriteString("-m")
+		}
+	}
+	return b.String()
+}
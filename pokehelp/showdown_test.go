@@ -0,0 +1,114 @@
+package pokehelp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShowdownRoundTrip(t *testing.T) {
+	paste := `Sparky (Pikachu) @ Light Ball
+Ability: Static
+Level: 50
+Shiny: Yes
+EVs: 4 HP / 252 SpA / 252 Spe
+Timid Nature
+IVs: 0 Atk
+- Thunderbolt
+- Volt Switch
+
+Mr-Mime
+Ability: Filter
+- Psychic
+`
+	sets, bad, err := ReadShowdown(strings.NewReader(paste))
+	if err != nil || len(bad) > 0 {
+		t.Fatalf("expected a clean read but got %v %v", err, bad)
+	}
+
+	want := ShowdownSet{
+		Nickname: "Sparky",
+		Species:  "pikachu",
+		Item:     "light-ball",
+		Ability:  "static",
+		Level:    50,
+		Shiny:    true,
+		Nature:   "timid",
+		EVs:      map[string]int{"hp": 4, "special-attack": 252, "speed": 252},
+		IVs:      map[string]int{"hp": 31, "attack": 0, "defense": 31, "special-attack": 31, "special-defense": 31, "speed": 31},
+		Moves:    []string{"thunderbolt", "volt-switch"},
+		Row:      1,
+	}
+	if len(sets) != 2 || !reflect.DeepEqual(sets[0], want) {
+		t.Fatalf("expected %+v but got %+v", want, sets)
+	}
+	if sets[1].Species != "mr-mime" || sets[1].Level != 100 || sets[1].Row != 2 {
+		t.Errorf("unexpected second set %+v", sets[1])
+	}
+
+	var b bytes.Buffer
+	if err := WriteShowdown(&b, sets); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != paste {
+		t.Errorf("expected the paste back but got:\n%s", b.String())
+	}
+}
+
+func TestReadShowdownReportsBadSets(t *testing.T) {
+	paste := "=== [gen9ou] Team ===\n\nPikachu\nEVs: 252 Luck\n- Growl\n\nEevee (F)\n- Tackle\n\nPichu\nFavorite color: yellow\n"
+
+	sets, bad, err := ReadShowdown(strings.NewReader(paste))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 1 || sets[0].Species != "eevee" || sets[0].Row != 2 {
+		t.Errorf("expected only eevee to be read but got %+v", sets)
+	}
+	if len(bad) != 2 || bad[0].Row != 1 || bad[1].Row != 3 {
+		t.Errorf("expected sets 1 and 3 to be reported but got %v", bad)
+	}
+}
+
+func TestShowdownSetValidate(t *testing.T) {
+	pikachu := &PokemonSummary{
+		Name:      "pikachu",
+		Abilities: []PokemonAbility{{Ability: NamedAPIResource[Ability]{Name: "static"}}},
+	}
+	moves := []PokemonMove{{Move: NamedAPIResource[Move]{Name: "thunderbolt"}}}
+
+	for _, tt := range []struct {
+		set ShowdownSet
+		err string
+	}{
+		{ShowdownSet{Level: 50, Ability: "static", Moves: []string{"thunderbolt"}}, ""},
+		{ShowdownSet{Level: 50, Ability: "huge-power", Moves: []string{"thunderbolt"}}, "can't have the ability"},
+		{ShowdownSet{Level: 50, Moves: []string{"surf"}}, "can't learn surf"},
+		{ShowdownSet{Level: 50}, "one to four moves"},
+		{ShowdownSet{Level: 101, Moves: []string{"thunderbolt"}}, "level"},
+		{ShowdownSet{Level: 50, Nature: "grumpy", Moves: []string{"thunderbolt"}}, "unknown nature"},
+		{ShowdownSet{Level: 50, EVs: map[string]int{"hp": 252, "attack": 252, "speed": 252}, Moves: []string{"thunderbolt"}}, "add up to 756"},
+		{ShowdownSet{Level: 50, IVs: map[string]int{"speed": 32}, Moves: []string{"thunderbolt"}}, "Spe IVs"},
+	} {
+		err := tt.set.Validate(pikachu, moves)
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("expected %q for %+v but got %v", tt.err, tt.set, err)
+		}
+	}
+}
+
+func TestToSlug(t *testing.T) {
+	for name, want := range map[string]string{
+		"Mr. Mime":      "mr-mime",
+		"Farfetch’d":    "farfetchd",
+		"Nidoran♀":      "nidoran-f",
+		"Thunder Punch": "thunder-punch",
+		" King's Rock ": "kings-rock",
+		"Porygon-Z":     "porygon-z",
+	} {
+		if got := ToSlug(name); got != want {
+			t.Errorf("expected %q for %q but got %q", want, name, got)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package pokehelp
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// snapshotWorkers is how many resources are downloaded at once,
+// the client's rate limiter still has the final say
+const snapshotWorkers = 4
+
+// DownloadSnapshot fetches every resource of the given kinds into the
+// store, skipping what is already there so an aborted download resumes.
+// progress is called after each resource.
+func DownloadSnapshot(ctx context.Context, client *Client, store *Store, endpoint func(kind string) string, kinds []string, progress func(kind string, done, total int)) error {
+	for _, kind := range kinds {
+		listUrl := endpoint(kind) + "?offset=0&limit=100000"
+		data, err := client.Get(ctx, listUrl)
+		if err != nil {
+			return err
+		}
+
+		var list ResourceList
+		if err := jsonUnmarshal(listUrl, data, &list); err != nil {
+			return err
+		}
+		if err := store.Put(listUrl, data); err != nil {
+			return err
+		}
+
+		urls := make(chan string)
+		var done atomic.Int32
+		var firstErr error
+		var errOnce sync.Once
+
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		for i := 0; i < snapshotWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for url := range urls {
+					if err := downloadResource(ctx, client, store, url); err != nil {
+						errOnce.Do(func() {
+							firstErr = err
+							cancel()
+						})
+						continue
+					}
+					progress(kind, int(done.Add(1)), len(list.Results))
+				}
+			}()
+		}
+
+	feed:
+		for _, v := range list.Results {
+			select {
+			case urls <- v.URL:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(urls)
+		wg.Wait()
+		cancel()
+
+		if firstErr != nil {
+			return firstErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func downloadResource(ctx context.Context, client *Client, store *Store, url string) error {
+	if store.Has(url) {
+		return nil
+	}
+
+	data, err := client.Get(ctx, url)
+	if errors.Is(err, ErrNotFound) {
+		// Listed but gone, nothing to keep
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return store.Put(url, data)
+}
+
+// ImportSnapshot copies the resources from a directory in the store's
+// layout, like a checkout of PokeAPI/api-data or another snapshot,
+// into store. Returns how many files were copied.
+func ImportSnapshot(store *Store, src string) (int, error) {
+	root := ""
+	for _, dir := range []string{filepath.Join(src, "data", "api", "v2"), filepath.Join(src, "api", "v2"), src} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			root = dir
+			break
+		}
+	}
+	if root == "" {
+		return 0, fs.ErrNotExist
+	}
+
+	imported := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "index.json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		// Only `<kind>/index.json` and `<kind>/<id>/index.json`
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		url := "/api/v2/" + strings.Join(parts[:len(parts)-1], "/") + "/"
+		if err := store.Put(url, data); err != nil {
+			return err
+		}
+		imported++
+
+		return nil
+	})
+
+	return imported, err
+}
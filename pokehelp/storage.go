@@ -0,0 +1,148 @@
+package pokehelp
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Storage is where profiles are saved, with what their trainers caught
+// and saw. Profiles keeps them as json files, pokestore in SQLite.
+type Storage interface {
+	// List is the names of the saved profiles, sorted
+	List() ([]string, error)
+	Exists(name string) bool
+	// Load reads a saved profile, ErrNoProfile if it was never saved
+	Load(name string) (*Profile, error)
+	Save(profile *Profile) error
+	Delete(name string) error
+	// QueryCaught is what the saved profile caught that matches filter,
+	// in the order it asks for
+	QueryCaught(profile string, filter PokedexFilter) ([]CaughtPokemon, error)
+}
+
+// ResponseCache keeps the API's responses by url, pokecache in memory
+// or pokestore in SQLite between sessions
+type ResponseCache interface {
+	Add(key string, val []byte)
+	// Get is the value if it is still fresh
+	Get(key string) ([]byte, bool)
+	// GetStale is the value even if it has expired, as a fallback when
+	// fetching fails
+	GetStale(key string) ([]byte, bool)
+	// Len is the number of responses kept
+	Len() int
+	// Clear throws out every response, it returns how many there were
+	Clear() int
+}
+
+// SeenRecord is a pokemon met while exploring, caught or not
+type SeenRecord struct {
+	FirstSeen time.Time `json:"first_seen"`
+	// Area is where it was first seen
+	Area  string `json:"area,omitempty"`
+	Times int    `json:"times"`
+}
+
+// MarkSeen records that the pokemon were met in area
+func (c *RequestConfig) MarkSeen(area string, names ...string) {
+	if c.Seen == nil {
+		c.Seen = map[string]SeenRecord{}
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, name := range names {
+		record, ok := c.Seen[name]
+		if !ok {
+			record = SeenRecord{FirstSeen: now, Area: area}
+		}
+		record.Times++
+		c.Seen[name] = record
+	}
+}
+
+// AddItem puts count of item in the inventory
+func (c *RequestConfig) AddItem(item string, count int) {
+	if c.Inventory == nil {
+		c.Inventory = map[string]int{}
+	}
+	c.Inventory[item] += count
+}
+
+// SeenCount is how many different pokemon were seen, the ones caught
+// count as seen too
+func (c *RequestConfig) SeenCount() int {
+	seen := len(c.Seen)
+	for name := range c.Pokedex {
+		if _, ok := c.Seen[name]; !ok {
+			seen++
+		}
+	}
+	return seen
+}
+
+// Orders a PokedexFilter can sort by
+const (
+	SortByName   = "name"
+	SortByLevel  = "level"
+	SortByCaught = "caught"
+)
+
+// PokedexSorts are the orders the pokedex can be listed in
+var PokedexSorts = []string{SortByName, SortByLevel, SortByCaught}
+
+// PokedexFilter picks caught pokemon by type and level. Zero values
+// don't filter, and ties in Sort are broken by name.
+type PokedexFilter struct {
+	Type     string
+	MinLevel int
+	MaxLevel int
+	Sort     string
+}
+
+// Match says if the caught pokemon passes the filter
+func (f PokedexFilter) Match(c CaughtPokemon) bool {
+	if f.Type != "" && !slices.ContainsFunc(c.Types, func(t PokemonType) bool { return t.Type.Name == f.Type }) {
+		return false
+	}
+	if f.MinLevel > 0 && c.Level < f.MinLevel {
+		return false
+	}
+	if f.MaxLevel > 0 && c.Level > f.MaxLevel {
+		return false
+	}
+	return true
+}
+
+// FilterCaught does what QueryCaught does on a pokedex in memory
+func FilterCaught(pokedex map[string]CaughtPokemon, filter PokedexFilter) []CaughtPokemon {
+	var caught []CaughtPokemon
+	for _, c := range pokedex {
+		if filter.Match(c) {
+			caught = append(caught, c)
+		}
+	}
+
+	sort.Slice(caught, func(i, j int) bool {
+		a, b := caught[i], caught[j]
+		switch {
+		case filter.Sort == SortByLevel && a.Level != b.Level:
+			return a.Level < b.Level
+		case filter.Sort == SortByCaught && !a.CaughtAt.Equal(b.CaughtAt):
+			return a.CaughtAt.Before(b.CaughtAt)
+		}
+		return a.Name < b.Name
+	})
+	return caught
+}
+
+// QueryCaught filters the session's pokedex, through Storage if it has
+// one so the queries use its indexes
+func (c *RequestConfig) QueryCaught(filter PokedexFilter) ([]CaughtPokemon, error) {
+	if c.Storage == nil || c.Profile == "" {
+		return FilterCaught(c.Pokedex, filter), nil
+	}
+	if err := c.SaveProfile(); err != nil {
+		return nil, err
+	}
+	return c.Storage.QueryCaught(c.Profile, filter)
+}
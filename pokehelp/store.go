@@ -0,0 +1,216 @@
+package pokehelp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrNotInSnapshot is returned in offline mode for anything that
+// wasn't downloaded or imported into the snapshot
+var ErrNotInSnapshot = errors.New("not in the offline snapshot")
+
+// SnapshotKinds are the resources `snapshot download` gets by default
+var SnapshotKinds = []string{"pokemon", "pokemon-species", "location-area", "move", "type", "region", "location"}
+
+// Store is a local copy of the API on disk. It uses the same layout as
+// the PokeAPI/api-data repo, `api/v2/<kind>/index.json` holds the full
+// list of a kind and `api/v2/<kind>/<id>/index.json` each resource, so
+// a checkout of that can be imported as is.
+type Store struct {
+	// Dir is where Put writes to, empty for a read only store
+	Dir string
+	// BaseURL is used for the next and previous urls of list pages
+	BaseURL string
+
+	fsys fs.FS
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir, BaseURL: DefaultAPIURL, fsys: os.DirFS(dir)}
+}
+
+// NewStoreFS is a read only store over fsys, like embedded fixtures
+func NewStoreFS(fsys fs.FS) *Store {
+	return &Store{BaseURL: DefaultAPIURL, fsys: fsys}
+}
+
+// DefaultStoreDir is where the snapshot lives unless told otherwise
+func DefaultStoreDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "pokedex-snapshot"
+	}
+	return filepath.Join(dir, "pokedex", "snapshot")
+}
+
+// Get serves rawUrl from the snapshot. List urls are paginated from the
+// stored full list and resources can be looked up by name or id.
+func (s *Store) Get(rawUrl string) ([]byte, error) {
+	kind, id, query, err := splitApiUrl(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		return s.getList(kind, query)
+	}
+
+	data, err := fs.ReadFile(s.fsys, s.resourcePath(kind, id))
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	// Commands use names, but the snapshot is stored by id
+	if _, convErr := strconv.Atoi(id); convErr != nil {
+		if realId, ok := s.lookupId(kind, id); ok {
+			if data, err := fs.ReadFile(s.fsys, s.resourcePath(kind, realId)); err == nil {
+				return data, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%s %s: %w", kind, id, ErrNotInSnapshot)
+}
+
+// Has checks if the resource at rawUrl is already in the snapshot
+func (s *Store) Has(rawUrl string) bool {
+	kind, id, _, err := splitApiUrl(rawUrl)
+	if err != nil {
+		return false
+	}
+
+	_, err = fs.Stat(s.fsys, s.resourcePath(kind, id))
+	return err == nil
+}
+
+// Put saves data as the resource (or the full list, if it's a list url)
+func (s *Store) Put(rawUrl string, data []byte) error {
+	kind, id, _, err := splitApiUrl(rawUrl)
+	if err != nil {
+		return err
+	}
+
+	if s.Dir == "" {
+		return errors.New("the store is read only")
+	}
+
+	path := filepath.Join(s.Dir, filepath.FromSlash(s.resourcePath(kind, id)))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Count is how many resources of kind are in the snapshot
+func (s *Store) Count(kind string) int {
+	entries, err := fs.ReadDir(s.fsys, path.Join("api", "v2", kind))
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, v := range entries {
+		if v.IsDir() {
+			count++
+		}
+	}
+	return count
+}
+
+// resourcePath is the slash separated path of a resource within the store
+func (s *Store) resourcePath(kind, id string) string {
+	return path.Join("api", "v2", kind, id, "index.json")
+}
+
+// getList pages through the stored full list like the API would
+func (s *Store) getList(kind string, query url.Values) ([]byte, error) {
+	list, err := s.readList(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	page := ResourceList{Count: len(list.Results)}
+	if offset < len(list.Results) {
+		page.Results = list.Results[offset:min(offset+limit, len(list.Results))]
+	}
+
+	base := strings.TrimSuffix(s.BaseURL, "/") + "/" + kind + "/"
+	if offset+limit < len(list.Results) {
+		page.Next = fmt.Sprintf("%s?offset=%d&limit=%d", base, offset+limit, limit)
+	}
+	if offset > 0 {
+		page.Previous = fmt.Sprintf("%s?offset=%d&limit=%d", base, max(offset-limit, 0), limit)
+	}
+
+	return json.Marshal(page)
+}
+
+func (s *Store) readList(kind string) (*ResourceList, error) {
+	data, err := fs.ReadFile(s.fsys, path.Join("api", "v2", kind, "index.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("list of %s: %w", kind, ErrNotInSnapshot)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list ResourceList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("unmarshalling list of %s: %w", kind, err)
+	}
+	return &list, nil
+}
+
+// lookupId finds the id of the resource of kind called name
+func (s *Store) lookupId(kind, name string) (string, bool) {
+	list, err := s.readList(kind)
+	if err != nil {
+		return "", false
+	}
+
+	for _, v := range list.Results {
+		if v.Name == name {
+			_, id, _, err := splitApiUrl(v.URL)
+			return id, err == nil && id != ""
+		}
+	}
+	return "", false
+}
+
+// splitApiUrl takes `https://pokeapi.co/api/v2/pokemon/25/` (or just the
+// path, like the api-data repo has) apart into the kind, id and query
+func splitApiUrl(rawUrl string) (string, string, url.Values, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	rest, ok := strings.CutPrefix(path.Clean(u.Path), "/api/v2/")
+	if !ok || rest == "" {
+		return "", "", nil, fmt.Errorf("%s isn't an API url", rawUrl)
+	}
+
+	kind, id, _ := strings.Cut(rest, "/")
+	if strings.Contains(id, "/") {
+		return "", "", nil, fmt.Errorf("%s isn't a resource url", rawUrl)
+	}
+
+	return kind, id, u.Query(), nil
+}
@@ -0,0 +1,36 @@
+package pokehelp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestStoreGet(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	list := `{"count":3,"results":[` +
+		`{"name":"bulbasaur","url":"https://pokeapi.co/api/v2/pokemon/1/"},` +
+		`{"name":"ivysaur","url":"https://pokeapi.co/api/v2/pokemon/2/"},` +
+		`{"name":"venusaur","url":"https://pokeapi.co/api/v2/pokemon/3/"}]}`
+	store.Put("https://pokeapi.co/api/v2/pokemon/?offset=0&limit=100000", []byte(list))
+	store.Put("https://pokeapi.co/api/v2/pokemon/2/", []byte(`{"name":"ivysaur"}`))
+
+	if data, err := store.Get("https://pokeapi.co/api/v2/pokemon/ivysaur"); err != nil || string(data) != `{"name":"ivysaur"}` {
+		t.Errorf("expected ivysaur by name but got %q, %v", data, err)
+	}
+
+	if _, err := store.Get("https://pokeapi.co/api/v2/pokemon/venusaur"); !errors.Is(err, ErrNotInSnapshot) {
+		t.Errorf("expected ErrNotInSnapshot but got %v", err)
+	}
+
+	data, err := store.Get("https://pokeapi.co/api/v2/pokemon/?offset=1&limit=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page ResourceList
+	json.Unmarshal(data, &page)
+	if page.Count != 3 || len(page.Results) != 1 || page.Results[0].Name != "ivysaur" || page.Next == "" || page.Previous == "" {
+		t.Errorf("unexpected page %+v", page)
+	}
+}
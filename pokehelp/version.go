@@ -0,0 +1,249 @@
+package pokehelp
+
+import (
+	"sort"
+	"strings"
+)
+
+// GameVersion is a main series game and where it sits in the API
+type GameVersion struct {
+	Name         string
+	VersionGroup string
+	Generation   string
+}
+
+// gameVersions are the games that can be picked with `game`,
+// keyed by the API's version name
+var gameVersions = map[string]GameVersion{}
+
+func init() {
+	groups := []struct {
+		versionGroup string
+		generation   string
+		versions     []string
+	}{
+		{"red-blue", "generation-i", []string{"red", "blue"}},
+		{"yellow", "generation-i", []string{"yellow"}},
+		{"gold-silver", "generation-ii", []string{"gold", "silver"}},
+		{"crystal", "generation-ii", []string{"crystal"}},
+		{"ruby-sapphire", "generation-iii", []string{"ruby", "sapphire"}},
+		{"emerald", "generation-iii", []string{"emerald"}},
+		{"firered-leafgreen", "generation-iii", []string{"firered", "leafgreen"}},
+		{"diamond-pearl", "generation-iv", []string{"diamond", "pearl"}},
+		{"platinum", "generation-iv", []string{"platinum"}},
+		{"heartgold-soulsilver", "generation-iv", []string{"heartgold", "soulsilver"}},
+		{"black-white", "generation-v", []string{"black", "white"}},
+		{"black-2-white-2", "generation-v", []string{"black-2", "white-2"}},
+		{"x-y", "generation-vi", []string{"x", "y"}},
+		{"omega-ruby-alpha-sapphire", "generation-vi", []string{"omega-ruby", "alpha-sapphire"}},
+		{"sun-moon", "generation-vii", []string{"sun", "moon"}},
+		{"ultra-sun-ultra-moon", "generation-vii", []string{"ultra-sun", "ultra-moon"}},
+		{"lets-go-pikachu-lets-go-eevee", "generation-vii", []string{"lets-go-pikachu", "lets-go-eevee"}},
+		{"sword-shield", "generation-viii", []string{"sword", "shield"}},
+		{"brilliant-diamond-and-shining-pearl", "generation-viii", []string{"brilliant-diamond", "shining-pearl"}},
+		{"legends-arceus", "generation-viii", []string{"legends-arceus"}},
+		{"scarlet-violet", "generation-ix", []string{"scarlet", "violet"}},
+	}
+
+	for _, group := range groups {
+		for _, version := range group.versions {
+			gameVersions[version] = GameVersion{
+				Name:         version,
+				VersionGroup: group.versionGroup,
+				Generation:   group.generation,
+			}
+		}
+	}
+}
+
+// LookupGameVersion finds a game by its version name, like "red" or "crystal"
+func LookupGameVersion(name string) (GameVersion, bool) {
+	v, ok := gameVersions[name]
+	return v, ok
+}
+
+// GameVersionNames lists all the known version names
+func GameVersionNames() []string {
+	names := make([]string, 0, len(gameVersions))
+	for name := range gameVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// SpriteFor returns the front sprite of the pokemon as it looked in game,
+// falling back to the default sprite for games without their own set
+func (s *PokemonSprites) SpriteFor(game GameVersion) string {
+	v := s.Versions
+
+	sprite := ""
+	switch game.VersionGroup {
+	case "red-blue":
+		sprite = v.GenerationI.RedBlue.FrontDefault
+	case "yellow":
+		sprite = v.GenerationI.Yellow.FrontDefault
+	case "gold-silver":
+		if game.Name == "silver" {
+			sprite = v.GenerationIi.Silver.FrontDefault
+		} else {
+			sprite = v.GenerationIi.Gold.FrontDefault
+		}
+	case "crystal":
+		sprite = v.GenerationIi.Crystal.FrontDefault
+	case "ruby-sapphire":
+		sprite = v.GenerationIii.RubySapphire.FrontDefault
+	case "emerald":
+		sprite = v.GenerationIii.Emerald.FrontDefault
+	case "firered-leafgreen":
+		sprite = v.GenerationIii.FireredLeafgreen.FrontDefault
+	case "diamond-pearl":
+		sprite = v.GenerationIv.DiamondPearl.FrontDefault
+	case "platinum":
+		sprite = v.GenerationIv.Platinum.FrontDefault
+	case "heartgold-soulsilver":
+		sprite = v.GenerationIv.HeartgoldSoulsilver.FrontDefault
+	case "black-white", "black-2-white-2":
+		sprite = v.GenerationV.BlackWhite.FrontDefault
+	case "x-y":
+		sprite = v.GenerationVi.XY.FrontDefault
+	case "omega-ruby-alpha-sapphire":
+		sprite = v.GenerationVi.OmegarubyAlphasapphire.FrontDefault
+	case "sun-moon", "ultra-sun-ultra-moon":
+		sprite = v.GenerationVii.UltraSunUltraMoon.FrontDefault
+	}
+
+	if sprite == "" {
+		return s.FrontDefault
+	}
+	return sprite
+}
+
+// SpriteVariants are the sprites Variant knows, genN is the front
+// sprite from a game of generation N
+var SpriteVariants = []string{"front", "shiny", "back", "back-shiny", "artwork", "gen1", "gen2", "gen3", "gen4", "gen5", "gen6", "gen7", "gen8"}
+
+// Variant returns the url of one of the SpriteVariants, empty if the
+// pokemon doesn't have that sprite
+func (s *PokemonSprites) Variant(name string) string {
+	v := s.Versions
+	switch name {
+	case "front":
+		return s.FrontDefault
+	case "shiny":
+		return s.FrontShiny
+	case "back":
+		return s.BackDefault
+	case "back-shiny":
+		return s.BackShiny
+	case "artwork":
+		return s.Other.OfficialArtwork.FrontDefault
+	case "gen1":
+		return v.GenerationI.RedBlue.FrontDefault
+	case "gen2":
+		return v.GenerationIi.Crystal.FrontDefault
+	case "gen3":
+		return v.GenerationIii.Emerald.FrontDefault
+	case "gen4":
+		return v.GenerationIv.Platinum.FrontDefault
+	case "gen5":
+		return v.GenerationV.BlackWhite.FrontDefault
+	case "gen6":
+		return v.GenerationVi.XY.FrontDefault
+	case "gen7":
+		return v.GenerationVii.UltraSunUltraMoon.FrontDefault
+	case "gen8":
+		return v.GenerationViii.Icons.FrontDefault
+	}
+	return ""
+}
+
+// FoundIn checks if the encounter happens in the given version
+func (e *PokemonEncounter) FoundIn(version string) bool {
+	for _, v := range e.VersionDetails {
+		if v.Version.Name == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Conditions are the sets of conditions under which the encounter
+// happens in version, or in any version if version is empty. It is nil
+// when the pokemon can be found there without any.
+func (e *PokemonEncounter) Conditions(version string) [][]NamedAPIResource[EncounterConditionValue] {
+	var sets [][]NamedAPIResource[EncounterConditionValue]
+	seen := map[string]bool{}
+	for _, v := range e.VersionDetails {
+		if version != "" && v.Version.Name != version {
+			continue
+		}
+
+		for _, detail := range v.EncounterDetails {
+			if len(detail.ConditionValues) == 0 {
+				return nil
+			}
+
+			names := make([]string, len(detail.ConditionValues))
+			for i, value := range detail.ConditionValues {
+				names[i] = value.Name
+			}
+			if key := strings.Join(names, ","); !seen[key] {
+				seen[key] = true
+				sets = append(sets, detail.ConditionValues)
+			}
+		}
+	}
+
+	return sets
+}
+
+// HeldItemRarity is how likely a pokemon is to hold an item in versions
+type HeldItemRarity struct {
+	Rarity   int
+	Versions []string
+}
+
+// Rarities groups the versions the item can be held in by how likely
+// that is, most likely first, looking only at version if it isn't empty
+func (h *PokemonHeldItem) Rarities(version string) []HeldItemRarity {
+	var rarities []HeldItemRarity
+	for _, v := range h.VersionDetails {
+		if version != "" && v.Version.Name != version {
+			continue
+		}
+
+		i := 0
+		for i < len(rarities) && rarities[i].Rarity != v.Rarity {
+			i++
+		}
+		if i == len(rarities) {
+			rarities = append(rarities, HeldItemRarity{Rarity: v.Rarity})
+		}
+		rarities[i].Versions = append(rarities[i].Versions, v.Version.Name)
+	}
+
+	sort.SliceStable(rarities, func(i, j int) bool {
+		return rarities[i].Rarity > rarities[j].Rarity
+	})
+	return rarities
+}
+
+// Levels is the range of levels the pokemon is met at in version, or in
+// any version if version is empty. Both are 0 if it isn't found there.
+func (e *PokemonEncounter) Levels(version string) (low, high int) {
+	for _, v := range e.VersionDetails {
+		if version != "" && v.Version.Name != version {
+			continue
+		}
+
+		for _, detail := range v.EncounterDetails {
+			if low == 0 || detail.MinLevel < low {
+				low = detail.MinLevel
+			}
+			high = max(high, detail.MaxLevel)
+		}
+	}
+	return low, high
+}
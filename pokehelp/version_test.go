@@ -0,0 +1,50 @@
+package pokehelp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEncounterConditions(t *testing.T) {
+	var encounter PokemonEncounter
+	json.Unmarshal([]byte(`{"pokemon":{"name":"hoothoot"},"version_details":[
+		{"version":{"name":"diamond"},"encounter_details":[
+			{"chance":10,"condition_values":[{"name":"time-night"}]},
+			{"chance":10,"condition_values":[{"name":"time-night"}]},
+			{"chance":5,"condition_values":[{"name":"time-morning"},{"name":"swarm-no"}]}]},
+		{"version":{"name":"pearl"},"encounter_details":[
+			{"chance":10,"condition_values":[]}]}]}`), &encounter)
+
+	var names [][]string
+	for _, set := range encounter.Conditions("diamond") {
+		var values []string
+		for _, v := range set {
+			values = append(values, v.Name)
+		}
+		names = append(names, values)
+	}
+	if want := [][]string{{"time-night"}, {"time-morning", "swarm-no"}}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v but got %v", want, names)
+	}
+
+	if conditions := encounter.Conditions(""); conditions != nil {
+		t.Errorf("expected no conditions when pearl has it anytime but got %v", conditions)
+	}
+}
+
+func TestHeldItemRarities(t *testing.T) {
+	var item PokemonHeldItem
+	json.Unmarshal([]byte(`{"item":{"name":"light-ball"},"version_details":[
+		{"rarity":5,"version":{"name":"yellow"}},
+		{"rarity":50,"version":{"name":"x"}},
+		{"rarity":5,"version":{"name":"crystal"}}]}`), &item)
+
+	want := []HeldItemRarity{{Rarity: 50, Versions: []string{"x"}}, {Rarity: 5, Versions: []string{"yellow", "crystal"}}}
+	if got := item.Rarities(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+	if got := item.Rarities("crystal"); len(got) != 1 || got[0].Versions[0] != "crystal" {
+		t.Errorf("expected only crystal but got %v", got)
+	}
+}
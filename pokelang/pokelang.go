@@ -0,0 +1,78 @@
+package pokelang
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLang is used when no language is set or a message is missing
+// from the selected catalog
+const DefaultLang = "en"
+
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+// catalogs maps a language code to its message key -> format string table
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	entries, err := catalogFS.ReadDir("catalogs")
+	if err != nil {
+		log.Fatalln("reading message catalogs failed")
+	}
+
+	loaded := map[string]map[string]string{}
+	for _, entry := range entries {
+		data, err := catalogFS.ReadFile(path.Join("catalogs", entry.Name()))
+		if err != nil {
+			log.Fatalf("reading catalog %s failed\n", entry.Name())
+		}
+
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			log.Fatalf("unmarshalling catalog %s failed\n", entry.Name())
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+
+	return loaded
+}
+
+// Supported lists the language codes that have a message catalog
+func Supported() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	return langs
+}
+
+// IsSupported reports if there is a message catalog for lang
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Message formats the message for key in lang, falling back to the
+// default language and then to the key itself
+func Message(lang, key string, args ...any) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		format, ok = catalogs[DefaultLang][key]
+	}
+	if !ok {
+		format = key
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
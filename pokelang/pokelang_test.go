@@ -0,0 +1,23 @@
+package pokelang
+
+import "testing"
+
+func TestCatalogsHaveAllKeys(t *testing.T) {
+	for _, lang := range Supported() {
+		for key := range catalogs[DefaultLang] {
+			if _, ok := catalogs[lang][key]; !ok {
+				t.Errorf("catalog %s is missing key %s", lang, key)
+			}
+		}
+	}
+}
+
+func TestMessageFallback(t *testing.T) {
+	if got := Message("xx", "catch.caught", "pikachu"); got != "pikachu was caught!" {
+		t.Errorf("expected fallback to default language but got %s instead", got)
+	}
+
+	if got := Message(DefaultLang, "no.such.key"); got != "no.such.key" {
+		t.Errorf("expected missing key to be returned as is but got %s instead", got)
+	}
+}
@@ -0,0 +1,46 @@
+package pokelog
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"os"
+)
+
+// Setup routes the default slog logger to stderr, or to logFile when set,
+// so stdout is left for the CLI's own output. Only warnings and errors
+// are logged unless verbose (info) or debug is set.
+// The returned closer closes the log file, if there is one.
+func Setup(verbose, debug bool, logFile string) (io.Closer, error) {
+	level := slog.LevelWarn
+	if verbose {
+		level = slog.LevelInfo
+	}
+	if debug {
+		level = slog.LevelDebug
+	}
+
+	var out io.WriteCloser = nopCloser{os.Stderr}
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		out = f
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})))
+
+	// SetDefault sends the log package through the handler at info level,
+	// which would hide log.Fatal messages, so give it a writer of its own
+	log.SetOutput(out)
+	log.SetFlags(log.LstdFlags)
+
+	return out, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,121 @@
+package pokesprite
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"io"
+	"strings"
+)
+
+// kittyChunk is the most base64 the Kitty protocol takes per escape
+const kittyChunk = 4096
+
+// writeKitty sends the PNG as is, Kitty decodes it on its side
+func writeKitty(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var out strings.Builder
+	for i := 0; i < len(encoded); i += kittyChunk {
+		chunk := encoded[i:min(i+kittyChunk, len(encoded))]
+		more := 0
+		if i+kittyChunk < len(encoded) {
+			more = 1
+		}
+
+		if i == 0 {
+			fmt.Fprintf(&out, "\x1b_Ga=T,f=100,m=%d;%s\x1b\\", more, chunk)
+		} else {
+			fmt.Fprintf(&out, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	out.WriteString("\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// writeSixel draws img with every pixel blown up to scale x scale, in
+// the closest colors of a 256 color palette. Transparent pixels are left
+// alone so the terminal's background shows through.
+func writeSixel(w io.Writer, img image.Image, scale int) error {
+	b := img.Bounds()
+	width, height := b.Dx()*scale, b.Dy()*scale
+	pal := color.Palette(palette.Plan9)
+
+	// Palette index of every pixel, -1 for transparent ones
+	pixels := make([]int, width*height)
+	used := map[int]bool{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.At(b.Min.X+x/scale, b.Min.Y+y/scale)
+			index := -1
+			if opaque(c) {
+				index = pal.Index(c)
+				used[index] = true
+			}
+			pixels[y*width+x] = index
+		}
+	}
+
+	var out strings.Builder
+	// P2=1 keeps pixels that aren't drawn transparent
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for index := range pal {
+		if used[index] {
+			r, g, b := rgb(pal[index])
+			fmt.Fprintf(&out, "#%d;2;%d;%d;%d", index, int(r)*100/255, int(g)*100/255, int(b)*100/255)
+		}
+	}
+
+	// Each sixel character is a column of 6 pixels, drawn one color at
+	// a time with $ going back to the start of the band
+	for top := 0; top < height; top += 6 {
+		for index := range pal {
+			if !used[index] {
+				continue
+			}
+
+			row := make([]byte, width)
+			drawn := false
+			for x := 0; x < width; x++ {
+				bits := 0
+				for k := 0; k < 6 && top+k < height; k++ {
+					if pixels[(top+k)*width+x] == index {
+						bits |= 1 << k
+					}
+				}
+				row[x] = byte(63 + bits)
+				drawn = drawn || bits != 0
+			}
+			if drawn {
+				fmt.Fprintf(&out, "#%d", index)
+				writeSixelRun(&out, row)
+				out.WriteString("$")
+			}
+		}
+		out.WriteString("-")
+	}
+	out.WriteString("\x1b\\\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// writeSixelRun writes a row of sixels, with !N repeating long runs
+func writeSixelRun(out *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, row[i])
+		} else {
+			out.Write(row[i:j])
+		}
+		i = j
+	}
+}
@@ -0,0 +1,251 @@
+// Package pokesprite draws sprites in the terminal, with the Kitty or
+// Sixel graphics protocols when the terminal has them and with colored
+// half-block characters everywhere else.
+package pokesprite
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Mode is how a sprite is drawn
+type Mode int
+
+const (
+	// Color256 uses half-blocks in the xterm 256 color palette, which
+	// about every terminal has
+	Color256 Mode = iota
+	// TrueColor uses half-blocks in 24 bit color
+	TrueColor
+	// Kitty sends the PNG itself with the Kitty graphics protocol
+	Kitty
+	// Sixel draws the pixels with DEC Sixel graphics
+	Sixel
+)
+
+// ModeEnv overrides what DetectMode guesses, one of kitty, sixel,
+// truecolor or 256
+const ModeEnv = "POKEDEX_GRAPHICS"
+
+// ParseMode is the mode called name, one of kitty, sixel, truecolor or 256
+func ParseMode(name string) (Mode, bool) {
+	switch strings.ToLower(name) {
+	case "kitty":
+		return Kitty, true
+	case "sixel":
+		return Sixel, true
+	case "truecolor", "24bit":
+		return TrueColor, true
+	case "256":
+		return Color256, true
+	}
+	return Color256, false
+}
+
+// DetectMode guesses what the terminal can do from the environment,
+// there's no asking a terminal that without reading from it
+func DetectMode(getenv func(string) string) Mode {
+	if mode, ok := ParseMode(getenv(ModeEnv)); ok {
+		return mode
+	}
+
+	term := getenv("TERM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || getenv("TERM_PROGRAM") == "ghostty":
+		return Kitty
+	case strings.Contains(term, "sixel") || term == "mlterm" || term == "foot" || getenv("TERM_PROGRAM") == "WezTerm":
+		return Sixel
+	}
+
+	switch getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return TrueColor
+	}
+	return Color256
+}
+
+// MaxWidth is how many columns a sprite drawn with half-blocks is
+// scaled down to at most
+const MaxWidth = 48
+
+// sixelScale blows the pixels up for Sixel, sprites are tiny otherwise
+const sixelScale = 2
+
+// MaxSixelWidth is how many pixels wide a Sixel image is at most, twice a
+// 96 pixel sprite. Artwork is scaled down to fit.
+const MaxSixelWidth = 192
+
+// Render draws a PNG in the terminal. Transparent borders are cropped
+// off and the rest is scaled down to fit MaxWidth, or MaxSixelWidth.
+func Render(w io.Writer, data []byte, mode Mode) error {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decoding sprite: %w", err)
+	}
+
+	if mode == Kitty {
+		// Kitty scales the PNG itself, cropping would only cost a re-encode
+		return writeKitty(w, data)
+	}
+
+	img = Crop(img)
+	if mode == Sixel {
+		scale := sixelScale
+		if img.Bounds().Dx()*scale > MaxSixelWidth {
+			scale = 1
+		}
+		if img.Bounds().Dx() > MaxSixelWidth {
+			img = Scale(img, MaxSixelWidth)
+		}
+		return writeSixel(w, img, scale)
+	}
+
+	if img.Bounds().Dx() > MaxWidth {
+		img = Scale(img, MaxWidth)
+	}
+	return writeBlocks(w, img, mode)
+}
+
+// Crop cuts off the transparent border around the sprite
+func Crop(img image.Image) image.Image {
+	b := img.Bounds()
+	box := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if opaque(img.At(x, y)) {
+				box = box.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if box.Empty() {
+		return img
+	}
+
+	crop := image.NewRGBA(image.Rect(0, 0, box.Dx(), box.Dy()))
+	for y := 0; y < box.Dy(); y++ {
+		for x := 0; x < box.Dx(); x++ {
+			crop.Set(x, y, img.At(box.Min.X+x, box.Min.Y+y))
+		}
+	}
+	return crop
+}
+
+// Scale shrinks img to width pixels wide, averaging the pixels that end
+// up in the same spot so thin outlines don't disappear
+func Scale(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	height := max(b.Dy()*width/b.Dx(), 1)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/height, b.Min.Y+(y+1)*b.Dy()/height
+		for x := 0; x < width; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/width, b.Min.X+(x+1)*b.Dx()/width
+
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < max(y1, y0+1); sy++ {
+				for sx := x0; sx < max(x1, x0+1); sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+pr, g+pg, bl+pb, a+pa, n+1
+				}
+			}
+			// Premultiplied, so averaging keeps transparent pixels from
+			// darkening the edges
+			scaled.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return scaled
+}
+
+func opaque(c color.Color) bool {
+	_, _, _, a := c.RGBA()
+	return a >= 0x8000
+}
+
+// writeBlocks draws two pixels per character with the upper half block,
+// the top pixel as the foreground color and the bottom one as background
+func writeBlocks(w io.Writer, img image.Image, mode Mode) error {
+	b := img.Bounds()
+	var out strings.Builder
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top := img.At(x, y)
+			bottom := color.Color(color.Transparent)
+			if y+1 < b.Max.Y {
+				bottom = img.At(x, y+1)
+			}
+
+			switch {
+			case opaque(top) && opaque(bottom):
+				out.WriteString(fg(top, mode) + bg(bottom, mode) + "▀")
+			case opaque(top):
+				out.WriteString("\x1b[49m" + fg(top, mode) + "▀")
+			case opaque(bottom):
+				out.WriteString("\x1b[49m" + fg(bottom, mode) + "▄")
+			default:
+				out.WriteString("\x1b[0m ")
+			}
+		}
+		out.WriteString("\x1b[0m\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func fg(c color.Color, mode Mode) string {
+	if mode == TrueColor {
+		r, g, b := rgb(c)
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm", xterm256(c))
+}
+
+func bg(c color.Color, mode Mode) string {
+	if mode == TrueColor {
+		r, g, b := rgb(c)
+		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", r, g, b)
+	}
+	return fmt.Sprintf("\x1b[48;5;%dm", xterm256(c))
+}
+
+// rgb is the color as 8 bit channels, without the premultiplied alpha
+func rgb(c color.Color) (uint8, uint8, uint8) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.R, n.G, n.B
+}
+
+// xterm256 picks the closest color of the 6x6x6 cube or the gray ramp
+// in the xterm palette
+func xterm256(c color.Color) int {
+	r, g, b := rgb(c)
+
+	level := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		return min((int(v)-35)/40, 5)
+	}
+	steps := [6]int{0, 95, 135, 175, 215, 255}
+	cr, cg, cb := level(r), level(g), level(b)
+	cubeDist := sq(steps[cr]-int(r)) + sq(steps[cg]-int(g)) + sq(steps[cb]-int(b))
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	gray := min(max((avg-3)/10, 0), 23)
+	grayValue := 8 + gray*10
+	grayDist := sq(grayValue-int(r)) + sq(grayValue-int(g)) + sq(grayValue-int(b))
+
+	if grayDist < cubeDist {
+		return 232 + gray
+	}
+	return 16 + 36*cr + 6*cg + cb
+}
+
+func sq(v int) int {
+	return v * v
+}
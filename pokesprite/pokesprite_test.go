@@ -0,0 +1,129 @@
+package pokesprite
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// testSprite is a red square with a blue bottom row in the middle of a
+// transparent 96x96 image, like the sprites have
+func testSprite(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 96, 96))
+	for y := 40; y < 50; y++ {
+		for x := 30; x < 40; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	for x := 30; x < 40; x++ {
+		img.Set(x, 49, color.NRGBA{B: 255, A: 255})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCropAndScale(t *testing.T) {
+	img, _ := png.Decode(bytes.NewReader(testSprite(t)))
+
+	cropped := Crop(img)
+	if cropped.Bounds().Dx() != 10 || cropped.Bounds().Dy() != 10 {
+		t.Fatalf("expected the 10x10 square but got %v", cropped.Bounds())
+	}
+
+	scaled := Scale(cropped, 5)
+	if scaled.Bounds().Dx() != 5 || scaled.Bounds().Dy() != 5 {
+		t.Fatalf("expected 5x5 but got %v", scaled.Bounds())
+	}
+	if r, _, b, _ := scaled.At(0, 4).RGBA(); r>>8 != 127 || b>>8 != 127 {
+		t.Errorf("expected the bottom row to mix red and blue but got %v", scaled.At(0, 4))
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	var out bytes.Buffer
+	if err := Render(&out, testSprite(t), TrueColor); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Errorf("expected two pixels per line but got %d lines", len(lines))
+	}
+	if !strings.Contains(lines[0], "\x1b[38;2;255;0;0m\x1b[48;2;255;0;0m▀") {
+		t.Errorf("expected red half blocks but got %q", lines[0])
+	}
+	if !strings.Contains(lines[4], "\x1b[48;2;0;0;255m") {
+		t.Errorf("expected a blue background on the last line but got %q", lines[4])
+	}
+
+	out.Reset()
+	if err := Render(&out, testSprite(t), Color256); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "\x1b[38;5;196m") {
+		t.Errorf("expected xterm red but got %q", out.String())
+	}
+}
+
+func TestRenderGraphics(t *testing.T) {
+	var out bytes.Buffer
+	if err := Render(&out, testSprite(t), Kitty); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "\x1b_Ga=T,f=100,m=0;iVBOR") {
+		t.Errorf("expected a single kitty escape with the PNG but got %q", out.String()[:30])
+	}
+
+	out.Reset()
+	if err := Render(&out, testSprite(t), Sixel); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.HasPrefix(s, "\x1bP0;1;0q\"1;1;20;20") || !strings.HasSuffix(s, "\x1b\\\n") {
+		t.Errorf("expected a 20x20 sixel image but got %q", s)
+	}
+
+	// Artwork is 475 pixels square, scaled down instead of blown up
+	artwork := image.NewNRGBA(image.Rect(0, 0, 475, 475))
+	for y := 0; y < 475; y++ {
+		for x := 0; x < 475; x++ {
+			artwork.Set(x, y, color.NRGBA{G: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, artwork); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := Render(&out, buf.Bytes(), Sixel); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.HasPrefix(s, "\x1bP0;1;0q\"1;1;192;192") {
+		t.Errorf("expected a %dx%[1]d sixel image but got %q", MaxSixelWidth, s[:min(len(s), 30)])
+	}
+}
+
+func TestDetectMode(t *testing.T) {
+	for _, tt := range []struct {
+		env  map[string]string
+		want Mode
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, Color256},
+		{map[string]string{"COLORTERM": "truecolor"}, TrueColor},
+		{map[string]string{"TERM": "xterm-kitty", "COLORTERM": "truecolor"}, Kitty},
+		{map[string]string{"TERM": "foot"}, Sixel},
+		{map[string]string{"TERM": "xterm-kitty", ModeEnv: "256"}, Color256},
+	} {
+		if got := DetectMode(func(key string) string { return tt.env[key] }); got != tt.want {
+			t.Errorf("expected %v for %v but got %v", tt.want, tt.env, got)
+		}
+	}
+}
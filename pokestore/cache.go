@@ -0,0 +1,81 @@
+package pokestore
+
+import (
+	"log/slog"
+	"time"
+)
+
+// responseRetention is how long responses are kept to be served stale,
+// like the name index they're rebuilt from the API after a week
+const responseRetention = 7 * 24 * time.Hour
+
+// Cache is the pokehelp.ResponseCache kept in the database, so responses
+// outlive the session and are there to fall back on the next time
+type Cache struct {
+	db       *DB
+	interval time.Duration
+}
+
+// Cache serves responses as fresh for interval. Responses older than a
+// week are thrown out.
+func (d *DB) Cache(interval time.Duration) *Cache {
+	if _, err := d.db.Exec("DELETE FROM responses WHERE fetched_at < ?", time.Now().Add(-responseRetention).UnixNano()); err != nil {
+		slog.Warn("reaping the response cache failed", "err", err)
+	}
+	return &Cache{db: d, interval: interval}
+}
+
+// Add keeps the response, failing to only logs as the response is
+// fetched again next time
+func (c *Cache) Add(key string, val []byte) {
+	_, err := c.db.db.Exec(`INSERT INTO responses (url, body, fetched_at) VALUES (?, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET body = excluded.body, fetched_at = excluded.fetched_at`,
+		key, val, time.Now().UnixNano())
+	if err != nil {
+		slog.Warn("caching the response failed", "url", key, "err", err)
+	}
+}
+
+// Get is the response if it is still fresh
+func (c *Cache) Get(key string) ([]byte, bool) {
+	val, fetchedAt, ok := c.get(key)
+	if !ok || time.Since(fetchedAt) > c.interval {
+		return nil, false
+	}
+	return val, true
+}
+
+// GetStale is the response however old it is
+func (c *Cache) GetStale(key string) ([]byte, bool) {
+	val, _, ok := c.get(key)
+	return val, ok
+}
+
+// Len is the number of responses kept, expired ones included
+func (c *Cache) Len() int {
+	var n int
+	if err := c.db.db.QueryRow("SELECT COUNT(*) FROM responses").Scan(&n); err != nil {
+		slog.Warn("counting the response cache failed", "err", err)
+	}
+	return n
+}
+
+// Clear throws out every response and returns how many there were
+func (c *Cache) Clear() int {
+	res, err := c.db.db.Exec("DELETE FROM responses")
+	if err != nil {
+		slog.Warn("clearing the response cache failed", "err", err)
+		return 0
+	}
+	n, _ := res.RowsAffected()
+	return int(n)
+}
+
+func (c *Cache) get(key string) ([]byte, time.Time, bool) {
+	var val []byte
+	var fetchedAt int64
+	if err := c.db.db.QueryRow("SELECT body, fetched_at FROM responses WHERE url = ?", key).Scan(&val, &fetchedAt); err != nil {
+		return nil, time.Time{}, false
+	}
+	return val, time.Unix(0, fetchedAt), true
+}
@@ -0,0 +1,421 @@
+// Package pokestore keeps profiles, what their trainers caught, saw and
+// hold, and the API's responses in an SQLite database, for pokedexes that have
+// outgrown the json files in pokehelp.Profiles.
+package pokestore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/munanadi/pokedex/pokehelp"
+	_ "modernc.org/sqlite"
+)
+
+// DB is the pokehelp.Storage kept in an SQLite database
+type DB struct {
+	db *sql.DB
+}
+
+// DefaultPath is pokedex/pokedex.db in the user config dir, next to the
+// profiles
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "pokedex.db")
+}
+
+// Open opens the database at path, creating it if needed, and migrates it
+// to the latest schema
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	// One writer at a time is all SQLite has anyway, and the pragmas are
+	// per connection
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// migrations are the schema, one step per version. The version the
+// database is at is kept in its user_version. Steps are only ever added,
+// never changed once released.
+var migrations = []string{
+	// 1: profiles with what they caught and saw
+	`CREATE TABLE profiles (
+		name   TEXT PRIMARY KEY,
+		region TEXT NOT NULL DEFAULT '',
+		area   TEXT NOT NULL DEFAULT '',
+		lang   TEXT NOT NULL DEFAULT '',
+		game   TEXT NOT NULL DEFAULT '',
+		team   TEXT NOT NULL DEFAULT '[]'
+	);
+	CREATE TABLE caught (
+		profile    TEXT NOT NULL REFERENCES profiles (name) ON DELETE CASCADE,
+		name       TEXT NOT NULL,
+		species_id INTEGER NOT NULL,
+		level      INTEGER NOT NULL,
+		caught_at  INTEGER NOT NULL,
+		data       TEXT NOT NULL,
+		PRIMARY KEY (profile, name)
+	);
+	CREATE INDEX caught_level ON caught (profile, level, name);
+	CREATE INDEX caught_caught_at ON caught (profile, caught_at, name);
+	CREATE TABLE caught_types (
+		profile TEXT NOT NULL,
+		name    TEXT NOT NULL,
+		type    TEXT NOT NULL,
+		PRIMARY KEY (profile, name, type),
+		FOREIGN KEY (profile, name) REFERENCES caught (profile, name) ON DELETE CASCADE
+	);
+	CREATE INDEX caught_types_type ON caught_types (profile, type, name);
+	CREATE TABLE seen (
+		profile    TEXT NOT NULL REFERENCES profiles (name) ON DELETE CASCADE,
+		name       TEXT NOT NULL,
+		first_seen INTEGER NOT NULL,
+		area       TEXT NOT NULL DEFAULT '',
+		times      INTEGER NOT NULL,
+		PRIMARY KEY (profile, name)
+	);`,
+
+	// 2: the response cache
+	`CREATE TABLE responses (
+		url        TEXT PRIMARY KEY,
+		body       BLOB NOT NULL,
+		fetched_at INTEGER NOT NULL
+	);
+	CREATE INDEX responses_fetched_at ON responses (fetched_at);`,
+
+	// 3: the items in each profile's inventory
+	`CREATE TABLE inventory (
+		profile TEXT NOT NULL REFERENCES profiles (name) ON DELETE CASCADE,
+		item    TEXT NOT NULL,
+		count   INTEGER NOT NULL,
+		PRIMARY KEY (profile, item)
+	);`,
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than this pokedex knows (%d)", version, len(migrations))
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("step %d: %w", version+1, err)
+		}
+		// PRAGMA doesn't take parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List is the names of the saved profiles, sorted
+func (d *DB) List() ([]string, error) {
+	rows, err := d.db.Query("SELECT name FROM profiles ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// Exists says if the profile has been saved
+func (d *DB) Exists(name string) bool {
+	var one int
+	return d.db.QueryRow("SELECT 1 FROM profiles WHERE name = ?", name).Scan(&one) == nil
+}
+
+// Load reads a saved profile, pokehelp.ErrNoProfile if it was never saved
+func (d *DB) Load(name string) (*pokehelp.Profile, error) {
+	profile := pokehelp.NewProfile(name)
+	var team string
+	err := d.db.QueryRow("SELECT region, area, lang, game, team FROM profiles WHERE name = ?", name).
+		Scan(&profile.Region, &profile.Area, &profile.Lang, &profile.Game, &team)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("loading profile %s: %w", name, pokehelp.ErrNoProfile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(team), &profile.Team); err != nil {
+		return nil, fmt.Errorf("loading the team of %s: %w", name, err)
+	}
+
+	caught, err := d.QueryCaught(name, pokehelp.PokedexFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range caught {
+		profile.Pokedex[c.Name] = c
+	}
+
+	rows, err := d.db.Query("SELECT name, first_seen, area, times FROM seen WHERE profile = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seenName string
+		var firstSeen int64
+		var record pokehelp.SeenRecord
+		if err := rows.Scan(&seenName, &firstSeen, &record.Area, &record.Times); err != nil {
+			return nil, err
+		}
+		record.FirstSeen = time.Unix(firstSeen, 0).UTC()
+		if profile.Seen == nil {
+			profile.Seen = map[string]pokehelp.SeenRecord{}
+		}
+		profile.Seen[seenName] = record
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadInventory(d.db, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func loadInventory(db *sql.DB, profile *pokehelp.Profile) error {
+	rows, err := db.Query("SELECT item, count FROM inventory WHERE profile = ?", profile.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item string
+		var count int
+		if err := rows.Scan(&item, &count); err != nil {
+			return err
+		}
+		if profile.Inventory == nil {
+			profile.Inventory = map[string]int{}
+		}
+		profile.Inventory[item] = count
+	}
+	return rows.Err()
+}
+
+// Save writes the profile in one transaction. Only the caught pokemon
+// that changed since it was last saved are written again.
+func (d *DB) Save(profile *pokehelp.Profile) error {
+	if !pokehelp.ValidProfileName(profile.Name) {
+		return fmt.Errorf("%q isn't a valid profile name", profile.Name)
+	}
+
+	team, err := json.Marshal(profile.Team)
+	if err != nil {
+		return err
+	}
+	if profile.Team == nil {
+		team = []byte("[]")
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO profiles (name, region, area, lang, game, team) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET region = excluded.region, area = excluded.area, lang = excluded.lang, game = excluded.game, team = excluded.team`,
+		profile.Name, profile.Region, profile.Area, profile.Lang, profile.Game, string(team)); err != nil {
+		return err
+	}
+	if err := saveCaught(tx, profile); err != nil {
+		return err
+	}
+	if err := saveSeen(tx, profile); err != nil {
+		return err
+	}
+	if err := saveInventory(tx, profile); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveCaught(tx *sql.Tx, profile *pokehelp.Profile) error {
+	saved := map[string]string{}
+	rows, err := tx.Query("SELECT name, data FROM caught WHERE profile = ?", profile.Name)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name, data string
+		if err := rows.Scan(&name, &data); err != nil {
+			rows.Close()
+			return err
+		}
+		saved[name] = data
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for name, c := range profile.Pokedex {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if old, ok := saved[name]; ok && old == string(data) {
+			delete(saved, name)
+			continue
+		}
+		delete(saved, name)
+
+		// Replacing the row drops its types with it
+		if _, err := tx.Exec("DELETE FROM caught WHERE profile = ? AND name = ?", profile.Name, name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO caught (profile, name, species_id, level, caught_at, data) VALUES (?, ?, ?, ?, ?, ?)",
+			profile.Name, name, c.SpeciesID(), c.Level, c.CaughtAt.UnixNano(), string(data)); err != nil {
+			return err
+		}
+		for _, t := range c.Types {
+			if _, err := tx.Exec("INSERT OR IGNORE INTO caught_types (profile, name, type) VALUES (?, ?, ?)", profile.Name, name, t.Type.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// What's left was released, or moved to another profile
+	for name := range saved {
+		if _, err := tx.Exec("DELETE FROM caught WHERE profile = ? AND name = ?", profile.Name, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveSeen(tx *sql.Tx, profile *pokehelp.Profile) error {
+	if _, err := tx.Exec("DELETE FROM seen WHERE profile = ?", profile.Name); err != nil {
+		return err
+	}
+	for name, record := range profile.Seen {
+		if _, err := tx.Exec("INSERT INTO seen (profile, name, first_seen, area, times) VALUES (?, ?, ?, ?, ?)",
+			profile.Name, name, record.FirstSeen.Unix(), record.Area, record.Times); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveInventory(tx *sql.Tx, profile *pokehelp.Profile) error {
+	if _, err := tx.Exec("DELETE FROM inventory WHERE profile = ?", profile.Name); err != nil {
+		return err
+	}
+	for item, count := range profile.Inventory {
+		if _, err := tx.Exec("INSERT INTO inventory (profile, item, count) VALUES (?, ?, ?)", profile.Name, item, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes a saved profile with everything it caught, saw and holds
+func (d *DB) Delete(name string) error {
+	res, err := d.db.Exec("DELETE FROM profiles WHERE name = ?", name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("deleting profile %s: %w", name, pokehelp.ErrNoProfile)
+	}
+	return nil
+}
+
+// QueryCaught is what the profile caught that matches filter, using the
+// level, caught_at and type indexes
+func (d *DB) QueryCaught(profile string, filter pokehelp.PokedexFilter) ([]pokehelp.CaughtPokemon, error) {
+	query := []string{"SELECT c.data FROM caught c WHERE c.profile = ?"}
+	args := []any{profile}
+	if filter.Type != "" {
+		query = append(query, "AND EXISTS (SELECT 1 FROM caught_types t WHERE t.profile = c.profile AND t.type = ? AND t.name = c.name)")
+		args = append(args, filter.Type)
+	}
+	if filter.MinLevel > 0 {
+		query = append(query, "AND c.level >= ?")
+		args = append(args, filter.MinLevel)
+	}
+	if filter.MaxLevel > 0 {
+		query = append(query, "AND c.level <= ?")
+		args = append(args, filter.MaxLevel)
+	}
+	switch filter.Sort {
+	case pokehelp.SortByLevel:
+		query = append(query, "ORDER BY c.level, c.name")
+	case pokehelp.SortByCaught:
+		query = append(query, "ORDER BY c.caught_at, c.name")
+	default:
+		query = append(query, "ORDER BY c.name")
+	}
+
+	rows, err := d.db.Query(strings.Join(query, " "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var caught []pokehelp.CaughtPokemon
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var c pokehelp.CaughtPokemon
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, err
+		}
+		caught = append(caught, c)
+	}
+	return caught, rows.Err()
+}
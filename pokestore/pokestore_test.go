@@ -0,0 +1,292 @@
+package pokestore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/munanadi/pokedex/pokehelp"
+)
+
+func openTestDB(t testing.TB) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "pokedex.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func caught(name string, level int, caughtAt time.Time, types ...string) pokehelp.CaughtPokemon {
+	c := pokehelp.CaughtPokemon{
+		PokemonSummary: pokehelp.PokemonSummary{
+			Name:    name,
+			Species: pokehelp.NamedAPIResource[pokehelp.PokemonSpecies]{Name: name, URL: fmt.Sprintf("https://pokeapi.co/api/v2/pokemon-species/%d/", level)},
+		},
+		Level:    level,
+		CaughtAt: caughtAt,
+	}
+	for i, t := range types {
+		c.Types = append(c.Types, pokehelp.PokemonType{Slot: i + 1, Type: pokehelp.NamedAPIResource[pokehelp.Type]{Name: t}})
+	}
+	return c
+}
+
+func TestSaveLoad(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.Load("ash"); !errors.Is(err, pokehelp.ErrNoProfile) {
+		t.Errorf("expected ErrNoProfile but got %v", err)
+	}
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	ash := &pokehelp.Profile{
+		Name: "ash",
+		Pokedex: map[string]pokehelp.CaughtPokemon{
+			"pikachu":   caught("pikachu", 12, day, "electric"),
+			"pidgeotto": caught("pidgeotto", 18, day.Add(time.Hour), "normal", "flying"),
+		},
+		Seen:      map[string]pokehelp.SeenRecord{"rattata": {FirstSeen: day, Area: "viridian-forest-area", Times: 3}},
+		Team:      []string{"pikachu"},
+		Inventory: map[string]int{"poke-ball": 5, "potion": 2},
+		Region:    "kanto",
+		Area:      "viridian-forest-area",
+		Lang:      "ja",
+		Game:      "yellow",
+	}
+	if err := db.Save(ash); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := db.Load("ash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, ash) {
+		t.Errorf("expected %+v but got %+v", ash, loaded)
+	}
+
+	// Saving again only has to write what changed, and drops what's gone
+	delete(ash.Pokedex, "pidgeotto")
+	pikachu := ash.Pokedex["pikachu"]
+	pikachu.Level = 13
+	ash.Pokedex["pikachu"] = pikachu
+	if err := db.Save(ash); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := db.Load("ash"); !reflect.DeepEqual(loaded.Pokedex, ash.Pokedex) {
+		t.Errorf("expected %+v but got %+v", ash.Pokedex, loaded.Pokedex)
+	}
+	if flying, _ := db.QueryCaught("ash", pokehelp.PokedexFilter{Type: "flying"}); len(flying) != 0 {
+		t.Errorf("expected pidgeotto's types to go with it but got %v", flying)
+	}
+
+	if err := db.Save(pokehelp.NewProfile("misty")); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := db.List(); !reflect.DeepEqual(names, []string{"ash", "misty"}) {
+		t.Errorf("unexpected profiles %v", names)
+	}
+	if err := db.Delete("ash"); err != nil {
+		t.Fatal(err)
+	}
+	var items int
+	db.db.QueryRow("SELECT COUNT(*) FROM inventory WHERE profile = 'ash'").Scan(&items)
+	if items != 0 {
+		t.Errorf("expected ash's inventory to go with the profile but %d items are left", items)
+	}
+	if db.Exists("ash") || !db.Exists("misty") {
+		t.Errorf("expected only misty to be left")
+	}
+	if err := db.Delete("ash"); !errors.Is(err, pokehelp.ErrNoProfile) {
+		t.Errorf("expected ErrNoProfile deleting twice but got %v", err)
+	}
+}
+
+func TestSaveInventory(t *testing.T) {
+	db := openTestDB(t)
+
+	profile := pokehelp.NewProfile("ash")
+	profile.Inventory = map[string]int{"poke-ball": 10, "great-ball": 1}
+	if err := db.Save(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	// Used up items are gone the next time it's saved
+	profile.Inventory = map[string]int{"poke-ball": 9}
+	if err := db.Save(profile); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := db.Load("ash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Inventory, profile.Inventory) {
+		t.Errorf("expected %v but got %v", profile.Inventory, loaded.Inventory)
+	}
+
+	if err := db.Save(pokehelp.NewProfile("misty")); err != nil {
+		t.Fatal(err)
+	}
+	if misty, _ := db.Load("misty"); misty.Inventory != nil {
+		t.Errorf("expected misty to start without items but got %v", misty.Inventory)
+	}
+}
+
+func TestQueryCaughtMatchesFilterCaught(t *testing.T) {
+	db := openTestDB(t)
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	profile := pokehelp.NewProfile("ash")
+	for i, c := range []pokehelp.CaughtPokemon{
+		caught("pikachu", 12, day.Add(3*time.Hour), "electric"),
+		caught("magnemite", 12, day.Add(1*time.Hour), "electric", "steel"),
+		caught("pidgey", 5, day.Add(2*time.Hour), "normal", "flying"),
+		caught("gyarados", 40, day, "water", "flying"),
+		caught("magikarp", 5, day.Add(4*time.Hour), "water"),
+	} {
+		c.ID = i
+		profile.Pokedex[c.Name] = c
+	}
+	if err := db.Save(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filter := range []pokehelp.PokedexFilter{
+		{},
+		{Type: "flying"},
+		{Type: "electric", Sort: pokehelp.SortByCaught},
+		{MinLevel: 10, Sort: pokehelp.SortByLevel},
+		{MaxLevel: 12, Sort: pokehelp.SortByLevel},
+		{Type: "water", MinLevel: 6},
+		{Type: "fire"},
+	} {
+		got, err := db.QueryCaught("ash", filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := pokehelp.FilterCaught(profile.Pokedex, filter)
+		if !reflect.DeepEqual(names(got), names(want)) {
+			t.Errorf("%+v: expected %v but got %v", filter, names(want), names(got))
+		}
+	}
+}
+
+func names(caught []pokehelp.CaughtPokemon) []string {
+	names := []string{}
+	for _, c := range caught {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex.db")
+	for i := 0; i < 2; i++ {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("opening %d: %s", i, err)
+		}
+		var version int
+		db.db.QueryRow("PRAGMA user_version").Scan(&version)
+		if version != len(migrations) {
+			t.Errorf("expected schema version %d but got %d", len(migrations), version)
+		}
+		db.Close()
+	}
+}
+
+func TestMigrateFromVersion2(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex.db")
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, step := range migrations[:2] {
+		if _, err := db.Exec(step); err != nil {
+			t.Fatalf("step %d: %s", i+1, err)
+		}
+	}
+	db.Exec("PRAGMA user_version = 2")
+	db.Exec("INSERT INTO profiles (name) VALUES ('ash')")
+	db.Close()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ash, err := store.Load("ash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ash.Inventory = map[string]int{"poke-ball": 3}
+	if err := store.Save(ash); err != nil {
+		t.Fatalf("expected the inventory table after migrating but got %s", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	db := openTestDB(t)
+	cache := db.Cache(time.Minute)
+
+	if _, ok := cache.Get("pokemon/pikachu"); ok {
+		t.Errorf("expected nothing cached yet")
+	}
+	cache.Add("pokemon/pikachu", []byte(`{"name":"pikachu"}`))
+	if val, ok := cache.Get("pokemon/pikachu"); !ok || string(val) != `{"name":"pikachu"}` {
+		t.Errorf("expected the response back but got %q %v", val, ok)
+	}
+
+	// An expired response is only served stale
+	db.db.Exec("UPDATE responses SET fetched_at = ?", time.Now().Add(-time.Hour).UnixNano())
+	if _, ok := cache.Get("pokemon/pikachu"); ok {
+		t.Errorf("expected an expired response not to be fresh")
+	}
+	if _, ok := cache.GetStale("pokemon/pikachu"); !ok {
+		t.Errorf("expected an expired response to be served stale")
+	}
+
+	if n := cache.Len(); n != 1 {
+		t.Errorf("expected 1 response cached but got %d", n)
+	}
+
+	// and after a week it's gone
+	db.db.Exec("UPDATE responses SET fetched_at = ?", time.Now().Add(-8*24*time.Hour).UnixNano())
+	if _, ok := db.Cache(time.Minute).GetStale("pokemon/pikachu"); ok {
+		t.Errorf("expected a response older than a week to be reaped")
+	}
+
+	cache.Add("pokemon/pikachu", []byte(`{"name":"pikachu"}`))
+	if n := cache.Clear(); n != 1 || cache.Len() != 0 {
+		t.Errorf("expected the one response to be cleared but got %d, %d left", n, cache.Len())
+	}
+}
+
+// BenchmarkQueryCaught filters a pokedex of a few thousand pokemon by type
+// and level
+func BenchmarkQueryCaught(b *testing.B) {
+	db := openTestDB(b)
+
+	types := []string{"normal", "fire", "water", "grass", "electric", "psychic"}
+	profile := pokehelp.NewProfile("ash")
+	for i := 0; i < 5000; i++ {
+		name := fmt.Sprintf("pokemon-%d", i)
+		profile.Pokedex[name] = caught(name, 1+i%100, time.Unix(int64(i), 0).UTC(), types[i%len(types)])
+	}
+	if err := db.Save(profile); err != nil {
+		b.Fatal(err)
+	}
+
+	filter := pokehelp.PokedexFilter{Type: "electric", MinLevel: 90, Sort: pokehelp.SortByLevel}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.QueryCaught("ash", filter); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/munanadi/pokedex/pokehelp"
+	"github.com/peterh/liner"
+)
+
+// historyPath is where the command history is kept between sessions
+func historyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "history")
+}
+
+// newLineEditor sets up the prompt with history from earlier sessions,
+// Ctrl-R search and tab completion
+func newLineEditor(config *pokehelp.RequestConfig) *liner.State {
+	line := liner.NewLiner()
+	line.SetCtrlCAborts(true)
+	line.SetTabCompletionStyle(liner.TabPrints)
+	line.SetWordCompleter(completeWord(config))
+
+	if f, err := os.Open(historyPath()); err == nil {
+		line.ReadHistory(f)
+		f.Close()
+	}
+
+	return line
+}
+
+// closeLineEditor saves the history and gives the terminal back
+func closeLineEditor(line *liner.State) {
+	defer line.Close()
+
+	path := historyPath()
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+
+	if f, err := os.Create(path); err == nil {
+		line.WriteHistory(f)
+		f.Close()
+	}
+}
+
+// lineReader is where the REPL gets its lines from, the liner prompt
+// or a script in tests
+type lineReader interface {
+	Prompt(prompt string) (string, error)
+	AppendHistory(item string)
+}
+
+// prompted is a line read at the prompt
+type prompted struct {
+	text string
+	err  error
+}
+
+// runRepl reads and runs commands until `exit`, the input ends or ctx is
+// cancelled. The prompt is read on another goroutine so a cancelled ctx
+// ends the session even while waiting for a line, commands only ever run
+// on this one.
+func runRepl(ctx context.Context, config *pokehelp.RequestConfig, line lineReader) {
+	for {
+		lines := make(chan prompted, 1)
+		go func() {
+			text, err := line.Prompt(config.Msg("prompt"))
+			lines <- prompted{text, err}
+		}()
+
+		var text string
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(config.Out)
+			return
+		case p := <-lines:
+			if errors.Is(p.err, liner.ErrPromptAborted) {
+				// Ctrl-C at the prompt just throws the line away
+				continue
+			}
+			if p.err != nil {
+				// Ctrl-D or the end of piped input
+				fmt.Fprintln(config.Out)
+				return
+			}
+			text = p.text
+		}
+		if strings.TrimSpace(text) != "" {
+			line.AppendHistory(text)
+		}
+
+		args := strings.Split(text, " ")
+		command, ok := getCommands()[args[0]]
+		if !ok {
+			continue
+		}
+
+		err := runCommand(ctx, config, command, args[1:])
+
+		// Saved after every command, a crash or a killed terminal loses nothing
+		if err := config.SaveProfile(); err != nil {
+			slog.Warn("saving the profile failed", "profile", config.Profile, "err", err)
+		}
+
+		if errors.Is(err, errExit) || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// runCommand runs a command with a context that Ctrl-C cancels, so an
+// interrupt aborts what the command is fetching instead of the REPL.
+// Cancelling parent aborts it as well.
+func runCommand(parent context.Context, config *pokehelp.RequestConfig, command cliCommand, args []string) error {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt)
+	defer stop()
+
+	config.Ctx = ctx
+	defer func() { config.Ctx = nil }()
+
+	err := command.callback(config, args)
+	switch {
+	case err == nil, errors.Is(err, errExit):
+	case ctx.Err() != nil:
+		fmt.Fprintln(config.Out, config.Msg("interrupted"))
+	case errors.Is(err, pokehelp.ErrNotFound):
+		fmt.Fprintln(config.Out, config.Msg("error.not_found"))
+	case errors.Is(err, pokehelp.ErrNotInSnapshot):
+		fmt.Fprintln(config.Out, config.Msg("error.not_in_snapshot", err))
+	case errors.Is(err, pokehelp.ErrCircuitOpen):
+		fmt.Fprintln(config.Out, config.Msg("error.unavailable"))
+	default:
+		fmt.Fprintln(config.Out, config.Msg("error", err))
+	}
+
+	return err
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/munanadi/pokedex/pokeapitest"
+	"github.com/munanadi/pokedex/pokecache"
+	"github.com/munanadi/pokedex/pokehelp"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// scriptReader feeds the REPL lines from config.In and echoes them after
+// the prompt, so the output reads like a terminal session
+type scriptReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (s *scriptReader) Prompt(prompt string) (string, error) {
+	fmt.Fprint(s.out, prompt)
+	if !s.scanner.Scan() {
+		return "", io.EOF
+	}
+	fmt.Fprintln(s.out, s.scanner.Text())
+	return s.scanner.Text(), nil
+}
+
+func (s *scriptReader) AppendHistory(item string) {}
+
+// newSessionConfig points a config at the pokeapitest fixtures with a
+// fixed seed for catching, so a session always prints the same thing
+func newSessionConfig(t *testing.T, script string) *pokehelp.RequestConfig {
+	t.Helper()
+
+	// Keep the name index saved by real sessions out of the tests
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	client := pokehelp.NewClient()
+	client.HTTP.Transport = pokeapitest.NewTransport()
+	client.Limiter = nil
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Stop)
+
+	return &pokehelp.RequestConfig{
+		Pager:   pokehelp.NewPager(pokehelp.DefaultPageSize),
+		Cache:   cache,
+		Client:  client,
+		Pokedex: map[string]pokehelp.CaughtPokemon{},
+		Lang:    "en",
+		Out:     &bytes.Buffer{},
+		In:      strings.NewReader(script),
+		Rand:    rand.New(rand.NewSource(1)),
+	}
+}
+
+// checkGolden compares got with testdata/golden/<name>.golden, or
+// rewrites the file when run with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file failed, run with -update to create it: %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s, run with -update if that is expected\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestReplSession(t *testing.T) {
+	script := strings.Join([]string{
+		"map",
+		"map",
+		"mapb",
+		"explore canalave-city-area",
+		"catch pikachu",
+		"catch pikachu",
+		"catch pikachu",
+		"catch magikarp",
+		"catch magikarp",
+		"inspect pikachu",
+		"inspect magikarp",
+		"pokedex",
+		"team add pikachu",
+		"team",
+		"team export",
+		"exit",
+	}, "\n")
+	config := newSessionConfig(t, script)
+
+	runRepl(context.Background(), config, &scriptReader{scanner: bufio.NewScanner(config.In), out: config.Out})
+
+	checkGolden(t, "session", config.Out.(*bytes.Buffer).Bytes())
+}
+
+func TestReplEndOfInput(t *testing.T) {
+	config := newSessionConfig(t, "help")
+
+	runRepl(context.Background(), config, &scriptReader{scanner: bufio.NewScanner(config.In), out: config.Out})
+
+	checkGolden(t, "help", config.Out.(*bytes.Buffer).Bytes())
+}
+
+// blockingReader gives the REPL its lines and then waits at the prompt
+// like a user who walked away
+type blockingReader struct {
+	lines   []string
+	waiting chan struct{}
+}
+
+func (b *blockingReader) Prompt(prompt string) (string, error) {
+	if len(b.lines) == 0 {
+		close(b.waiting)
+		select {}
+	}
+	line := b.lines[0]
+	b.lines = b.lines[1:]
+	return line, nil
+}
+
+func (b *blockingReader) AppendHistory(item string) {}
+
+func TestReplCancelledAtPrompt(t *testing.T) {
+	config := newSessionConfig(t, "")
+	config.Storage = pokehelp.NewProfiles(t.TempDir())
+	config.UseProfile(pokehelp.NewProfile("ash"))
+	config.Pokedex = map[string]pokehelp.CaughtPokemon{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := &blockingReader{lines: []string{"team add pikachu"}, waiting: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		runRepl(ctx, config, reader)
+		close(done)
+	}()
+
+	<-reader.waiting
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the REPL to end when cancelled at the prompt")
+	}
+}